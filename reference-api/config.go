@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/oci"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/bitbucket"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/git"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/gitea"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/gitlab"
+)
+
+// Config is the optional file based configuration loaded from the path in REFERENCE_API_CONFIG
+type Config struct {
+	// Sources route repositories to backends other than github.com
+	Sources []SourceConfig `json:"sources"`
+
+	// Latest is the default policy for choosing the latest reference of a repository
+	Latest sources.LatestPolicy `json:"latest"`
+
+	// RequireSignatures flags deployed references without a verified signature by default
+	RequireSignatures bool `json:"requireSignatures"`
+
+	// RefRules extract the gitRef from image tags, tried in order after those of the repository
+	RefRules []sources.RefRule `json:"refRules"`
+
+	// ResolutionOrder is the order gitRefs are tried as each kind of reference, defaulting to release, tag, commit
+	ResolutionOrder sources.ResolutionOrder `json:"resolutionOrder"`
+
+	// PullSecrets are paths of .dockerconfigjson files, such as mounted image pull secrets, holding the credentials
+	// images are read from their registry with
+	PullSecrets []string `json:"pullSecrets"`
+
+	// Registries are the hosts of the registries images are read from anonymously, such as "ghcr.io". Images are only
+	// read from these and from the registries of the pull secrets.
+	Registries []string `json:"registries"`
+
+	// Repositories override the defaults for individual repositories, keyed by the repo query parameter
+	Repositories map[string]RepositoryConfig `json:"repositories"`
+
+	// Applications hold the settings of individual Argo CD applications, keyed as "<namespace>:<name>"
+	Applications map[string]ApplicationConfig `json:"applications"`
+}
+
+// RepositoryConfig holds the settings of a single repository. Fields left unset keep the defaults.
+type RepositoryConfig struct {
+	Latest sources.LatestPolicy `json:"latest"`
+
+	// Branch is tracked by commit deployments, defaulting to REFERENCE_API_DEFAULT_REF
+	Branch string `json:"branch"`
+
+	// RequireSignatures flags deployed references without a verified signature
+	RequireSignatures *bool `json:"requireSignatures"`
+
+	// RefRules extract the gitRef from the image tags of the repository, tried before the default ones
+	RefRules []sources.RefRule `json:"refRules"`
+
+	// ResolutionOrder is the order gitRefs of the repository are tried as each kind of reference
+	ResolutionOrder sources.ResolutionOrder `json:"resolutionOrder"`
+}
+
+// ApplicationConfig holds the settings of a single Argo CD application
+type ApplicationConfig struct {
+	// The tag scope limits the application to its own release stream of a monorepo
+	sources.TagScope
+}
+
+// SourceConfig describes a source backend and the repositories routed to it
+type SourceConfig struct {
+	Type      string `json:"type"`      // Backend type: "github", "gitlab", "gitea", "forgejo", "bitbucket" or "git"
+	Host      string `json:"host"`      // Routes "<host>/<path>" repositories to this source as "<path>"
+	Prefix    string `json:"prefix"`    // Routes repositories starting with prefix to this source unchanged
+	BaseURL   string `json:"baseURL"`   // Base URL of the backend instance
+	TokenEnv  string `json:"tokenEnv"`  // Environment variable holding the API token
+	TokenFile string `json:"tokenFile"` // File holding the API token
+
+	// GitHub Enterprise Server
+	UploadURL      string `json:"uploadURL"`      // Upload API base URL, defaults to baseURL
+	AppID          string `json:"appID"`          // GitHub App ID
+	PrivateKeyPath string `json:"privateKeyPath"` // Path to the GitHub App private key file
+	AuthMode       string `json:"authMode"`       // "app", "token" or "none". Inferred from the fields set when empty
+	StrictAuth     bool   `json:"strictAuth"`     // Refuse to start or serve with broken credentials
+
+	// Bitbucket
+	Flavor   string `json:"flavor"`   // "cloud" (default) or "datacenter"
+	Username string `json:"username"` // Account username, only needed for Bitbucket Cloud app passwords
+
+	// Plain git remotes
+	RemoteURL     string   `json:"remoteURL"`     // Remote URL, optionally containing a "{repo}" placeholder
+	MirrorDir     string   `json:"mirrorDir"`     // Directory holding the local mirrors
+	FetchInterval string   `json:"fetchInterval"` // How long a mirror is used before fetching again, e.g. "5m"
+	Repositories  []string `json:"repositories"`  // Patterns of the repositories that may be mirrored, e.g. "team/*"
+	MaxMirrors    int      `json:"maxMirrors"`    // How many repositories are mirrored at most, 50 by default
+}
+
+// loadConfig reads the configuration file at path, returning an empty configuration when path is empty
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	if err := cfg.Latest.Validate(); err != nil {
+		return nil, fmt.Errorf("latest: %v", err)
+	}
+	for i, rule := range cfg.RefRules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("refRules %d: %v", i, err)
+		}
+	}
+	if err := cfg.ResolutionOrder.Validate(); err != nil {
+		return nil, fmt.Errorf("resolutionOrder: %v", err)
+	}
+	for repo, rc := range cfg.Repositories {
+		if err := rc.Latest.Validate(); err != nil {
+			return nil, fmt.Errorf("repository %s: latest: %v", repo, err)
+		}
+		if err := rc.ResolutionOrder.Validate(); err != nil {
+			return nil, fmt.Errorf("repository %s: resolutionOrder: %v", repo, err)
+		}
+		for i, rule := range rc.RefRules {
+			if err := rule.Validate(); err != nil {
+				return nil, fmt.Errorf("repository %s: refRules %d: %v", repo, i, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// latestPolicy returns the policy for choosing the latest reference of repo
+func (c *Config) latestPolicy(repo string) sources.LatestPolicy {
+	if c == nil {
+		return sources.LatestPolicy{}
+	}
+	return c.Latest.Override(c.Repositories[repo].Latest)
+}
+
+// branch returns the branch configured to be tracked by commit deployments of repo, or "" if none is
+func (c *Config) branch(repo string) string {
+	if c == nil {
+		return ""
+	}
+	return c.Repositories[repo].Branch
+}
+
+// requireSignatures reports whether deployed references of repo without a verified signature are flagged
+func (c *Config) requireSignatures(repo string) bool {
+	if c == nil {
+		return false
+	}
+	if require := c.Repositories[repo].RequireSignatures; require != nil {
+		return *require
+	}
+	return c.RequireSignatures
+}
+
+// refRules returns the rules extracting the gitRef from the image tags of repo, in the order they are tried
+func (c *Config) refRules(repo string) []sources.RefRule {
+	if c == nil {
+		return nil
+	}
+	return append(append([]sources.RefRule{}, c.Repositories[repo].RefRules...), c.RefRules...)
+}
+
+// resolutionOrder returns the order gitRefs of repo are tried as each kind of reference
+func (c *Config) resolutionOrder(repo string) sources.ResolutionOrder {
+	if c == nil {
+		return sources.DefaultResolutionOrder
+	}
+	if order := c.Repositories[repo].ResolutionOrder; len(order) > 0 {
+		return order
+	}
+	if len(c.ResolutionOrder) > 0 {
+		return c.ResolutionOrder
+	}
+	return sources.DefaultResolutionOrder
+}
+
+// tagScope returns the release stream configured for the Argo CD application app
+func (c *Config) tagScope(app string) sources.TagScope {
+	if c == nil {
+		return sources.TagScope{}
+	}
+	return c.Applications[app].TagScope
+}
+
+// token returns the API token configured for the source, or "" if none is configured
+func (sc SourceConfig) token() (string, error) {
+	if sc.TokenFile != "" {
+		data, err := os.ReadFile(sc.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %v", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	if sc.TokenEnv != "" {
+		return os.Getenv(sc.TokenEnv), nil
+	}
+	return "", nil
+}
+
+// instanceURL returns the URL of the backend instance used to route repositories by default
+func (sc SourceConfig) instanceURL() string {
+	if sc.Type == "bitbucket" && sc.bitbucketFlavor() == bitbucket.Cloud {
+		// The API lives on its own host, but repositories are known by the web one
+		return "https://bitbucket.org"
+	}
+	if sc.BaseURL != "" {
+		return sc.BaseURL
+	}
+	return sc.RemoteURL
+}
+
+// bitbucketFlavor returns the configured Bitbucket flavor, defaulting to Bitbucket Cloud
+func (sc SourceConfig) bitbucketFlavor() bitbucket.Flavor {
+	if sc.Flavor == "" {
+		return bitbucket.Cloud
+	}
+	return bitbucket.Flavor(sc.Flavor)
+}
+
+// newSource creates the backend described by the configuration
+func (sc SourceConfig) newSource() (sources.Source, error) {
+	token, err := sc.token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch sc.Type {
+	case "github":
+		if sc.BaseURL == "" {
+			return nil, fmt.Errorf("github source requires a baseURL")
+		}
+		config := github.Config{
+			BaseURL:        sc.BaseURL,
+			UploadURL:      sc.UploadURL,
+			AppID:          sc.AppID,
+			PrivateKeyPath: sc.PrivateKeyPath,
+			AuthMode:       github.AuthMode(sc.AuthMode),
+			TokenFile:      sc.TokenFile,
+			StrictAuth:     sc.StrictAuth,
+		}
+		if sc.TokenFile == "" {
+			config.Token = token
+		}
+		return github.NewSource(config)
+	case "gitlab":
+		if sc.BaseURL == "" {
+			return nil, fmt.Errorf("gitlab source requires a baseURL")
+		}
+		return gitlab.NewSource(sc.BaseURL, token, nil), nil
+	case "gitea", "forgejo":
+		if sc.BaseURL == "" {
+			return nil, fmt.Errorf("%s source requires a baseURL", sc.Type)
+		}
+		return gitea.NewSource(sc.BaseURL, token, nil), nil
+	case "bitbucket":
+		return bitbucket.NewSource(sc.bitbucketFlavor(), sc.BaseURL, sc.Username, token, nil)
+	case "git":
+		if sc.RemoteURL == "" || sc.MirrorDir == "" {
+			return nil, fmt.Errorf("git source requires a remoteURL and a mirrorDir")
+		}
+		var interval time.Duration
+		if sc.FetchInterval != "" {
+			if interval, err = time.ParseDuration(sc.FetchInterval); err != nil {
+				return nil, fmt.Errorf("invalid fetchInterval: %v", err)
+			}
+		}
+		for _, pattern := range sc.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid repository pattern %q: %v", pattern, err)
+			}
+		}
+		return git.NewSource(git.Config{
+			RemoteURL:     sc.RemoteURL,
+			MirrorDir:     sc.MirrorDir,
+			FetchInterval: interval,
+			Repositories:  sc.Repositories,
+			MaxMirrors:    sc.MaxMirrors,
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown source type %q", sc.Type)
+}
+
+// newRegistry creates a registry routing configured repositories to their sources
+// and everything else to the GitHub instance configured through the environment
+func newRegistry(cfg *Config) (*sources.Registry, error) {
+	defaultSource, err := github.NewSource(github.ConfigFromEnv())
+	if err != nil {
+		return nil, fmt.Errorf("default github source: %v", err)
+	}
+	registry := sources.NewRegistry(defaultSource)
+
+	for i, sc := range cfg.Sources {
+		source, err := sc.newSource()
+		if err != nil {
+			return nil, fmt.Errorf("source %d (%s): %v", i, sc.Type, err)
+		}
+
+		switch {
+		case sc.Prefix != "":
+			registry.RegisterPrefix(sc.Prefix, source)
+		case sc.Host != "":
+			registry.RegisterHost(sc.Host, source)
+		default:
+			// Route by the host of the instance when nothing else is configured
+			u, err := url.Parse(sc.instanceURL())
+			if err != nil || u.Host == "" {
+				return nil, fmt.Errorf("source %d (%s): a host or prefix is required", i, sc.Type)
+			}
+			registry.RegisterHost(u.Host, source)
+		}
+	}
+
+	return registry, nil
+}
+
+// newImageClient creates the client reading the labels of images from the configured registries and those of the
+// configured pull secrets
+func newImageClient(cfg *Config) (*oci.Client, error) {
+	credentials, err := oci.LoadDockerConfig(cfg.PullSecrets...)
+	if err != nil {
+		return nil, err
+	}
+	return oci.NewClient(nil, credentials, cfg.Registries), nil
+}
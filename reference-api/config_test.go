@@ -0,0 +1,253 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/bitbucket"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/git"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/gitea"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/gitlab"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeConfig writes a configuration file to a temporary directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfigWithoutPath(t *testing.T) {
+	cfg, err := loadConfig("")
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Sources)
+}
+
+func TestNewRegistryFromConfig(t *testing.T) {
+	t.Setenv("TEST_GITLAB_TOKEN", "secret")
+	t.Setenv("TEST_GHE_TOKEN", "ghp_secret")
+
+	cfg, err := loadConfig(writeConfig(t, `{
+		"sources": [
+			{"type": "gitlab", "baseURL": "https://gitlab.example.com", "tokenEnv": "TEST_GITLAB_TOKEN"},
+			{"type": "gitlab", "baseURL": "https://gitlab.example.com", "prefix": "legacy"},
+			{"type": "git", "remoteURL": "https://git.example.com/{repo}.git", "mirrorDir": "/tmp/mirrors"},
+			{"type": "forgejo", "baseURL": "https://forgejo.example.com"},
+			{"type": "bitbucket"},
+			{"type": "github", "baseURL": "https://ghe.example.com/api/v3/", "authMode": "token", "tokenEnv": "TEST_GHE_TOKEN"},
+			{"type": "bitbucket", "flavor": "datacenter", "baseURL": "https://bitbucket.example.com"}
+		]
+	}`))
+	assert.NoError(t, err)
+
+	registry, err := newRegistry(cfg)
+	assert.NoError(t, err)
+
+	source, repo := registry.Lookup("gitlab.example.com/group/project")
+	assert.IsType(t, &gitlab.Source{}, source)
+	assert.Equal(t, "group/project", repo)
+
+	source, repo = registry.Lookup("legacy/project")
+	assert.IsType(t, &gitlab.Source{}, source)
+	assert.Equal(t, "legacy/project", repo)
+
+	source, repo = registry.Lookup("git.example.com/team/app")
+	assert.IsType(t, &git.Source{}, source)
+	assert.Equal(t, "team/app", repo)
+
+	source, repo = registry.Lookup("forgejo.example.com/team/app")
+	assert.IsType(t, &gitea.Source{}, source)
+	assert.Equal(t, "team/app", repo)
+
+	source, repo = registry.Lookup("bitbucket.org/workspace/app")
+	assert.IsType(t, &bitbucket.Source{}, source)
+	assert.Equal(t, "workspace/app", repo)
+
+	source, repo = registry.Lookup("bitbucket.example.com/PROJECT/app")
+	assert.IsType(t, &bitbucket.Source{}, source)
+	assert.Equal(t, "PROJECT/app", repo)
+
+	source, repo = registry.Lookup("ghe.example.com/org/repo")
+	if assert.IsType(t, &github.Source{}, source) {
+		assert.Equal(t, github.AuthModeToken, source.(*github.Source).AuthMode())
+	}
+	assert.Equal(t, "org/repo", repo)
+
+	source, repo = registry.Lookup("mozilla/repo")
+	assert.IsType(t, &github.Source{}, source)
+	assert.Equal(t, "mozilla/repo", repo)
+}
+
+func TestNewRegistryInvalidSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name:   "Unknown type",
+			config: `{"sources": [{"type": "svn", "host": "svn.example.com"}]}`,
+		},
+		{
+			name:   "GitHub without base URL",
+			config: `{"sources": [{"type": "github", "host": "ghe.example.com"}]}`,
+		},
+		{
+			name: "GitHub token mode without a token",
+			config: `{"sources": [
+				{"type": "github", "baseURL": "https://ghe.example.com/api/v3/", "authMode": "token"}
+			]}`,
+		},
+		{
+			name:   "GitLab without base URL",
+			config: `{"sources": [{"type": "gitlab", "host": "gitlab.example.com"}]}`,
+		},
+		{
+			name:   "Unknown Bitbucket flavor",
+			config: `{"sources": [{"type": "bitbucket", "flavor": "server", "host": "bitbucket.example.com"}]}`,
+		},
+		{
+			name:   "Git remote without a host to route by",
+			config: `{"sources": [{"type": "git", "remoteURL": "file:///srv/git", "mirrorDir": "/tmp/mirrors"}]}`,
+		},
+		{
+			name: "Invalid fetch interval",
+			config: `{"sources": [
+				{"type": "git", "remoteURL": "https://git.example.com", "mirrorDir": "/tmp/mirrors", "fetchInterval": "soon"}
+			]}`,
+		},
+		{
+			name: "Invalid repository pattern",
+			config: `{"sources": [
+				{"type": "git", "remoteURL": "https://git.example.com", "mirrorDir": "/tmp/mirrors", "repositories": ["team/["]}
+			]}`,
+		},
+		{
+			name: "Missing token file",
+			config: `{"sources": [
+				{"type": "gitlab", "baseURL": "https://gitlab.example.com", "tokenFile": "/nonexistent"}
+			]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(writeConfig(t, tt.config))
+			assert.NoError(t, err)
+
+			_, err = newRegistry(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewRegistryInvalidDefaultSource(t *testing.T) {
+	t.Setenv("GITHUB_AUTH_MODE", "token")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN_FILE", "")
+
+	_, err := newRegistry(&Config{})
+	assert.ErrorContains(t, err, "default github source")
+}
+
+func TestLoadConfigLatestPolicy(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `{
+		"latest": {"strategy": "version"},
+		"repositories": {
+			"mozilla/monorepo": {"latest": {"tagPrefixes": ["app-name/v"]}},
+			"mozilla/legacy": {"latest": {"strategy": "date"}}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t,
+		sources.LatestPolicy{Strategy: sources.LatestByVersion, TagPrefixes: []string{"app-name/v"}},
+		cfg.latestPolicy("mozilla/monorepo"))
+	assert.Equal(t, sources.LatestPolicy{Strategy: sources.LatestByDate}, cfg.latestPolicy("mozilla/legacy"))
+	assert.Equal(t, sources.LatestPolicy{Strategy: sources.LatestByVersion}, cfg.latestPolicy("mozilla/other"))
+
+	_, err = loadConfig(writeConfig(t, `{"repositories": {"mozilla/app": {"latest": {"strategy": "newest"}}}}`))
+	assert.ErrorContains(t, err, "repository mozilla/app")
+
+	_, err = loadConfig(writeConfig(t, `{"latest": {"excludeTags": ["nightly-("]}}`))
+	assert.ErrorContains(t, err, `invalid pattern "nightly-("`)
+}
+
+func TestLoadConfigApplications(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `{
+		"applications": {
+			"argocd:api": {"tagPrefix": "api/"},
+			"argocd:worker": {"tagPattern": "^worker/v[0-9]+"}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, sources.TagScope{Prefix: "api/"}, cfg.tagScope("argocd:api"))
+	assert.Equal(t, sources.TagScope{Pattern: sources.MustParsePattern("^worker/v[0-9]+")}, cfg.tagScope("argocd:worker"))
+	assert.Equal(t, sources.TagScope{}, cfg.tagScope("argocd:other"))
+
+	_, err = loadConfig(writeConfig(t, `{"applications": {"argocd:api": {"tagPattern": "api/("}}}`))
+	assert.ErrorContains(t, err, `invalid pattern "api/("`)
+}
+
+func TestLoadConfigRequireSignatures(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `{
+		"requireSignatures": true,
+		"repositories": {
+			"mozilla/legacy": {"requireSignatures": false}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	assert.True(t, cfg.requireSignatures("mozilla/app"))
+	assert.False(t, cfg.requireSignatures("mozilla/legacy"))
+
+	var unset *Config
+	assert.False(t, unset.requireSignatures("mozilla/app"))
+}
+
+func TestLoadConfigRefRules(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `{
+		"refRules": [{"name": "sha", "pattern": "^sha-(?P<sha>[0-9a-f]+)$"}],
+		"repositories": {
+			"mozilla/app": {"refRules": [{"name": "version-sha", "pattern": "^(?P<tag>[0-9.]+)-(?P<sha>[0-9a-f]+)$"}]}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []sources.RefRule{
+		{Name: "version-sha", Pattern: sources.MustParsePattern("^(?P<tag>[0-9.]+)-(?P<sha>[0-9a-f]+)$")},
+		{Name: "sha", Pattern: sources.MustParsePattern("^sha-(?P<sha>[0-9a-f]+)$")},
+	}, cfg.refRules("mozilla/app"))
+	assert.Equal(t, []sources.RefRule{{Name: "sha", Pattern: sources.MustParsePattern("^sha-(?P<sha>[0-9a-f]+)$")}}, cfg.refRules("mozilla/other"))
+
+	_, err = loadConfig(writeConfig(t, `{"refRules": [{"pattern": "^(?P<version>.+)$"}]}`))
+	assert.ErrorContains(t, err, "refRules 0")
+
+	_, err = loadConfig(writeConfig(t, `{"repositories": {"mozilla/app": {"refRules": [{"pattern": "^(?P<version>.+)$"}]}}}`))
+	assert.ErrorContains(t, err, "repository mozilla/app: refRules 0")
+
+	_, err = loadConfig(writeConfig(t, `{"refRules": [{"pattern": "("}]}`))
+	assert.ErrorContains(t, err, `invalid pattern "("`)
+}
+
+func TestLoadConfigResolutionOrder(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `{
+		"resolutionOrder": ["tag", "commit"],
+		"repositories": {"mozilla/app": {"resolutionOrder": ["commit"]}}
+	}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, sources.ResolutionOrder{sources.KindCommit}, cfg.resolutionOrder("mozilla/app"))
+	assert.Equal(t, sources.ResolutionOrder{sources.KindTag, sources.KindCommit}, cfg.resolutionOrder("mozilla/other"))
+	assert.Equal(t, sources.DefaultResolutionOrder, (&Config{}).resolutionOrder("mozilla/other"))
+
+	_, err = loadConfig(writeConfig(t, `{"resolutionOrder": ["branch"]}`))
+	assert.ErrorContains(t, err, "resolutionOrder: unknown kind")
+
+	_, err = loadConfig(writeConfig(t, `{"repositories": {"mozilla/app": {"resolutionOrder": ["tag", "tag"]}}}`))
+	assert.ErrorContains(t, err, "repository mozilla/app: resolutionOrder")
+}
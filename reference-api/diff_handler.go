@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+const (
+	defaultPerPage = 30  // Commits listed per page when the request does not say
+	maxPerPage     = 100 // Most commits listed per page
+)
+
+// DiffHandler lists the commits between the deployed gitRef and the latest reference, paginated
+func (deps *HandlerDeps) DiffHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := deps.parseReferenceRequest(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := parsePage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	source, repoPath, ok := deps.lookupSource(w, r, req)
+	if !ok {
+		return
+	}
+
+	cacheKey := fmt.Sprintf("diff:%s:%d:%d", req.cacheKey(), page.Number, page.Size)
+	if cachedResponse, ok := deps.getFromCache(cacheKey); ok {
+		w.WriteHeader(cachedResponse.StatusCode)
+		_, _ = w.Write(cachedResponse.Body)
+		return
+	}
+
+	var pending *sources.PendingCommits
+	output, err := deps.references(r.Context(), source, repoPath, req)
+	if err == nil {
+		pending, err = sources.Pending(r.Context(), source, repoPath, output, page)
+	}
+
+	statusCode, body := encodeResult(pending, err)
+	writeResult(w, statusCode, body)
+	deps.storeInCache(cacheKey, statusCode, body)
+}
+
+// parsePage reads the page and per_page query parameters
+func parsePage(r *http.Request) (sources.Page, error) {
+	page := sources.Page{Number: 1, Size: defaultPerPage}
+	if p := r.URL.Query().Get("page"); p != "" {
+		number, err := strconv.Atoi(p)
+		if err != nil || number < 1 {
+			return page, fmt.Errorf("Invalid 'page' query parameter: %s", p)
+		}
+		page.Number = number
+	}
+	if pp := r.URL.Query().Get("per_page"); pp != "" {
+		size, err := strconv.Atoi(pp)
+		if err != nil || size < 1 || size > maxPerPage {
+			return page, fmt.Errorf("Invalid 'per_page' query parameter: %s. Expected 1 to %d", pp, maxPerPage)
+		}
+		page.Size = size
+	}
+	return page, nil
+}
+
+// references returns the current and latest references of the lookup, sharing the cache of UnifiedHandler
+func (deps *HandlerDeps) references(
+	ctx context.Context, source sources.Source, repoPath string, req *referenceRequest,
+) (*sources.StandardizedOutput, error) {
+	cacheKey := req.cacheKey()
+	if cachedResponse, ok := deps.getFromCache(cacheKey); ok && cachedResponse.StatusCode == http.StatusOK {
+		var output sources.StandardizedOutput
+		if err := json.Unmarshal(cachedResponse.Body, &output); err == nil {
+			return &output, nil
+		}
+		log.Printf("Error decoding cached response for key: %s", cacheKey)
+	}
+
+	output, err := deps.resolve(ctx, source, repoPath, req)
+	statusCode, body := encodeResult(output, err)
+	deps.storeInCache(cacheKey, statusCode, body)
+	return output, err
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/stretchr/testify/assert"
+)
+
+// listingSource is a mockSource with five commits between any reference and latest, counting its lookups
+type listingSource struct {
+	mockSource
+	resolved int
+}
+
+func (l *listingSource) ResolveCurrent(ctx context.Context, q sources.Query) (*sources.StandardizedEntity, error) {
+	l.resolved++
+	return l.mockSource.ResolveCurrent(ctx, q)
+}
+
+func (l *listingSource) ListCommitsBetween(
+	ctx context.Context, repo, current, latest string, page sources.Page,
+) ([]*sources.StandardizedEntity, bool, error) {
+	const total = 5
+	var commits []*sources.StandardizedEntity
+	for i := (page.Number - 1) * page.Size; i < min(page.Number*page.Size, total); i++ {
+		commits = append(commits, &sources.StandardizedEntity{Ref: fmt.Sprintf("commit-%d", i)})
+	}
+	return commits, page.Number*page.Size < total, nil
+}
+
+// newTestDeps creates handler dependencies serving every repository from source
+func newTestDeps(t *testing.T, source sources.Source) *HandlerDeps {
+	cache, err := lru.NewWithEvict[string, CachedResponse](10, onEvict)
+	assert.NoError(t, err, "Failed to initialize cache")
+	return &HandlerDeps{
+		Sources: sources.NewRegistry(source),
+		cache:   cache,
+		config: cacheConfiguration{
+			SuccessCacheDuration: 24 * time.Hour,
+			ErrorCacheDuration:   1 * time.Hour,
+		},
+	}
+}
+
+// serveLookup serves a lookup of gitRef v1.0.0 of test/repo with the given extra query parameters
+func serveLookup(deps *HandlerDeps, handler http.HandlerFunc, path string, params url.Values) *httptest.ResponseRecorder {
+	params.Set("repo", "test/repo")
+	params.Set("gitRef", "v1.0.0")
+	req := httptest.NewRequest("GET", path+"?"+params.Encode(), nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestDiffHandler(t *testing.T) {
+	source := &listingSource{mockSource: mockSource{found: func(sources.Kind, string) bool { return true }}}
+	deps := newTestDeps(t, source)
+
+	tests := []struct {
+		name     string
+		params   url.Values
+		wantRefs []string
+		wantNext int
+	}{
+		{
+			name:     "First page",
+			params:   url.Values{"per_page": {"2"}},
+			wantRefs: []string{"commit-0", "commit-1"},
+			wantNext: 2,
+		},
+		{
+			name:     "Last page",
+			params:   url.Values{"per_page": {"2"}, "page": {"3"}},
+			wantRefs: []string{"commit-4"},
+		},
+		{
+			name:     "Default page size",
+			params:   url.Values{},
+			wantRefs: []string{"commit-0", "commit-1", "commit-2", "commit-3", "commit-4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveLookup(deps, deps.DiffHandler, "/api/references/diff", tt.params)
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			var pending sources.PendingCommits
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &pending))
+			assert.Equal(t, "v1.0.0", pending.Current.Ref)
+			assert.Equal(t, "latest", pending.Latest.Ref)
+			var refs []string
+			for _, commit := range pending.Commits {
+				refs = append(refs, commit.Ref)
+			}
+			assert.Equal(t, tt.wantRefs, refs)
+			assert.Equal(t, tt.wantNext, pending.NextPage)
+		})
+	}
+
+	// Every page shares the references resolved for the first one
+	assert.Equal(t, 1, source.resolved)
+}
+
+func TestDiffHandlerSharesUnifiedCache(t *testing.T) {
+	source := &listingSource{mockSource: mockSource{found: func(sources.Kind, string) bool { return true }}}
+	deps := newTestDeps(t, source)
+
+	rr := serveLookup(deps, deps.UnifiedHandler, "/api/references", url.Values{})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = serveLookup(deps, deps.DiffHandler, "/api/references/diff", url.Values{})
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	assert.Equal(t, 1, source.resolved)
+}
+
+func TestDiffHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		source         sources.Source
+		params         url.Values
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid page size",
+			source:         &listingSource{mockSource: mockSource{found: func(sources.Kind, string) bool { return true }}},
+			params:         url.Values{"per_page": {"500"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid 'per_page' query parameter: 500. Expected 1 to 100\n",
+		},
+		{
+			name:           "Invalid page",
+			source:         &listingSource{mockSource: mockSource{found: func(sources.Kind, string) bool { return true }}},
+			params:         url.Values{"page": {"0"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid 'page' query parameter: 0\n",
+		},
+		{
+			name:           "Source unable to list commits",
+			source:         &mockSource{found: func(sources.Kind, string) bool { return true }},
+			params:         url.Values{},
+			expectedStatus: http.StatusNotImplemented,
+			expectedBody:   `{"error":"unsupported by source: the source cannot list commits between references"}` + "\n",
+		},
+		{
+			name:           "Reference not found",
+			source:         &listingSource{mockSource: mockSource{found: func(sources.Kind, string) bool { return false }}},
+			params:         url.Values{},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"reference not found: commit v1.0.0"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestDeps(t, tt.source)
+			rr := serveLookup(deps, deps.DiffHandler, "/api/references/diff", tt.params)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
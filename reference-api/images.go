@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/oci"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// imageDigestCacheDuration is how long the labels of images looked up by digest, which never change, are cached
+const imageDigestCacheDuration = 24 * time.Hour
+
+// builtImage is the repository and commit an image was built from, as told by its OCI labels.
+// Either is empty when the image lacks its label.
+type builtImage struct {
+	Repo     string `json:"repo"`
+	Revision string `json:"revision"`
+}
+
+// lookupImage reads the repository and commit the image was built from out of its labels
+func (deps *HandlerDeps) lookupImage(ctx context.Context, image string) (*builtImage, error) {
+	if deps.Images == nil {
+		return nil, fmt.Errorf("%w: image lookups are not configured", sources.ErrUnsupported)
+	}
+	ref, err := oci.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", sources.ErrInvalidRef, err)
+	}
+
+	cacheKey := "image:" + image
+	if cachedResponse, ok := deps.getFromCache(cacheKey); ok && cachedResponse.StatusCode == http.StatusOK {
+		var built builtImage
+		if err := json.Unmarshal(cachedResponse.Body, &built); err == nil {
+			return &built, nil
+		}
+	}
+
+	labels, err := deps.Images.Labels(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	built := &builtImage{Repo: sourceRepository(labels[oci.LabelSource]), Revision: labels[oci.LabelRevision]}
+
+	// Tags may be pushed again, so only the labels of digests outlive the responses
+	if body, err := json.Marshal(built); err == nil {
+		ttl := time.Duration(0)
+		if ref.Digest != "" {
+			ttl = imageDigestCacheDuration
+		}
+		deps.storeInCacheFor(cacheKey, http.StatusOK, body, ttl)
+	}
+	return built, nil
+}
+
+// sourceRepository returns the repository, as given in the repo query parameter, of the URL of an
+// org.opencontainers.image.source label. Repositories of github.com are known by their path and others by their host
+// and path. It returns "" when the URL is empty or invalid.
+func sourceRepository(source string) string {
+	if source == "" {
+		return ""
+	}
+	if !strings.Contains(source, "://") {
+		source = "https://" + source
+	}
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if path == "" {
+		return ""
+	}
+	if u.Host == "github.com" {
+		return path
+	}
+	return u.Host + "/" + path
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/oci"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/oci/ocitest"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedHandlerImage(t *testing.T) {
+	const revision = "abc123def4567890abc123def4567890abc123de"
+
+	registry := ocitest.NewRegistry(t)
+	digest := registry.Push("mozilla/app", "v1.2.3", map[string]string{
+		oci.LabelSource:   "https://github.com/test/repo.git",
+		oci.LabelRevision: revision,
+	})
+	registry.Push("mozilla/unlabelled", "v1.0.0", nil)
+
+	deps := newTestDeps(t, &mockSource{found: func(kind sources.Kind, gitRef string) bool {
+		return kind == sources.KindCommit || gitRef == "v1.0.0"
+	}})
+	deps.Images = oci.NewClient(registry.Client(), nil, []string{registry.Host()})
+	handler := http.HandlerFunc(deps.UnifiedHandler)
+
+	tests := []struct {
+		name       string
+		params     url.Values
+		wantStatus int
+		wantRef    string
+	}{
+		{
+			name:       "By digest",
+			params:     url.Values{"image": {registry.Host() + "/mozilla/app@" + digest}},
+			wantStatus: http.StatusOK,
+			wantRef:    revision,
+		},
+		{
+			name:       "By tag",
+			params:     url.Values{"image": {registry.Host() + "/mozilla/app:v1.2.3"}},
+			wantStatus: http.StatusOK,
+			wantRef:    revision,
+		},
+		{
+			name:       "Explicit gitRef wins over the revision label",
+			params:     url.Values{"image": {registry.Host() + "/mozilla/app:v1.2.3"}, "gitRef": {"v1.0.0"}},
+			wantStatus: http.StatusOK,
+			wantRef:    "v1.0.0",
+		},
+		{
+			name:       "Missing labels",
+			params:     url.Values{"image": {registry.Host() + "/mozilla/unlabelled:v1.0.0"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Missing image",
+			params:     url.Values{"image": {registry.Host() + "/mozilla/app:v9.9.9"}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Registry not allowed",
+			params:     url.Values{"image": {"127.0.0.1:1/mozilla/app:v1.2.3"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Parent path segment",
+			params:     url.Values{"image": {registry.Host() + "/mozilla/../app:v1.2.3"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid image",
+			params:     url.Values{"image": {registry.Host() + "/mozilla/app:"}},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/references?"+tt.params.Encode(), nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var output sources.StandardizedOutput
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &output))
+			assert.Equal(t, tt.wantRef, output.Current.Ref)
+		})
+	}
+
+	// Labels are cached, so images are only read once from the registry
+	requests := registry.Requests()
+	req := httptest.NewRequest("GET", "/api/references?image="+url.QueryEscape(registry.Host()+"/mozilla/app@"+digest), nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, requests, registry.Requests())
+}
+
+func TestUnifiedHandlerImageCacheDuration(t *testing.T) {
+	registry := ocitest.NewRegistry(t)
+	digest := registry.Push("mozilla/app", "v1.2.3", map[string]string{
+		oci.LabelSource:   "https://github.com/test/repo.git",
+		oci.LabelRevision: "abc123def4567890abc123def4567890abc123de",
+	})
+	deps := newTestDeps(t, &mockSource{found: func(sources.Kind, string) bool { return true }})
+	deps.Images = oci.NewClient(registry.Client(), nil, []string{registry.Host()})
+	deps.config.SuccessCacheDuration = 0
+	handler := http.HandlerFunc(deps.UnifiedHandler)
+
+	serve := func(image string) {
+		req := httptest.NewRequest("GET", "/api/references?image="+url.QueryEscape(image), nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	// The labels of a tag expire with the responses, as the tag may be pushed again
+	serve(registry.Host() + "/mozilla/app:v1.2.3")
+	requests := registry.Requests()
+	serve(registry.Host() + "/mozilla/app:v1.2.3")
+	assert.Greater(t, registry.Requests(), requests)
+
+	// Those of a digest are kept
+	serve(registry.Host() + "/mozilla/app@" + digest)
+	requests = registry.Requests()
+	serve(registry.Host() + "/mozilla/app@" + digest)
+	assert.Equal(t, requests, registry.Requests())
+}
+
+func TestUnifiedHandlerImageUnconfigured(t *testing.T) {
+	deps := newTestDeps(t, &mockSource{found: func(sources.Kind, string) bool { return true }})
+	req := httptest.NewRequest("GET", "/api/references?image=ghcr.io/mozilla/app:v1.2.3", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(deps.UnifiedHandler).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
+
+func TestSourceRepository(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "https://github.com/mozilla/app", want: "mozilla/app"},
+		{source: "https://github.com/mozilla/app.git", want: "mozilla/app"},
+		{source: "github.com/mozilla/app/", want: "mozilla/app"},
+		{source: "https://gitlab.example.com/group/sub/app", want: "gitlab.example.com/group/sub/app"},
+		{source: "https://github.com", want: ""},
+		{source: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			assert.Equal(t, tt.want, sourceRepository(tt.source))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,7 +10,6 @@ import (
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
-	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/github"
 )
 
 func onEvict(key string, value CachedResponse) {
@@ -60,16 +60,47 @@ func main() {
 		}
 	}
 
+	cfg, err := loadConfig(os.Getenv("REFERENCE_API_CONFIG"))
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// GitHub serves every repository that is not routed to another source
+	registry, err := newRegistry(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure sources: %v", err)
+	}
+
+	images, err := newImageClient(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure image lookups: %v", err)
+	}
+
+	// Refuse to start when strict sources have broken credentials
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	err = verifySources(ctx, registry)
+	cancel()
+	if err != nil {
+		log.Fatalf("Failed to verify source credentials: %v", err)
+	}
+
 	deps := &HandlerDeps{
-		CommitsHandler:  github.CommitsHandler,
-		ReleasesHandler: github.ReleasesHandler,
-		TagsHandler:     github.TagsHandler,
-		cache:           cache,
-		config:          cacheConfig,
+		Sources:       registry,
+		Images:        images,
+		Config:        cfg,
+		DefaultBranch: os.Getenv("REFERENCE_API_DEFAULT_REF"),
+		cache:         cache,
+		config:        cacheConfig,
 	}
 
-	// Unified handler for both releases and commits, may support additional sources in the future.
+	// Unified handler for releases, tags and commits across all registered sources.
 	http.HandleFunc("/api/references", deps.UnifiedHandler)
+	// Commits between the deployed reference and the latest one
+	http.HandleFunc("/api/references/diff", deps.DiffHandler)
+	// Releases between the deployed reference and the latest one, with their notes
+	http.HandleFunc("/api/references/releases", deps.ReleasesHandler)
+	// Auth mode and health of the configured sources
+	http.HandleFunc("/api/status", deps.StatusHandler)
 
 	port := "8000"
 	if p := os.Getenv("PORT"); p != "" {
@@ -1,55 +1,55 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
 	"github.com/stretchr/testify/assert"
 )
 
-// Mock handlers for testing
-func mockCommitsHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	if _, err := fmt.Fprint(w, `{"handler": "commits"}`); err != nil {
-		log.Printf("Error writing response: %v", err)
-	}
+// mockSource finds a gitRef only as the kinds for which found returns true
+type mockSource struct {
+	found func(kind sources.Kind, gitRef string) bool
 }
 
-func mockReleasesHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	if _, err := fmt.Fprint(w, `{"handler": "releases"}`); err != nil {
-		log.Printf("Error writing response: %v", err)
+func (m *mockSource) ResolveCurrent(ctx context.Context, q sources.Query) (*sources.StandardizedEntity, error) {
+	if !m.found(q.Kind, q.GitRef) {
+		return nil, fmt.Errorf("%w: %s %s", sources.ErrNotFound, q.Kind, q.GitRef)
 	}
+	return &sources.StandardizedEntity{Ref: q.GitRef, Message: string(q.Kind)}, nil
 }
 
-func mockTagsHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	if _, err := fmt.Fprint(w, `{"handler": "tags"}`); err != nil {
-		log.Printf("Error writing response: %v", err)
-	}
+func (m *mockSource) ResolveLatest(ctx context.Context, q sources.Query) (*sources.StandardizedEntity, error) {
+	return &sources.StandardizedEntity{Ref: "latest", Message: string(q.Kind)}, nil
 }
 
-// Mock release handler that simulates a 404 (to trigger tag fallback)
-func mockReleasesHandler404(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotFound)
-	if _, err := fmt.Fprint(w, `Not Found`); err != nil {
-		log.Printf("Error writing response: %v", err)
-	}
+// newMockRegistry creates a registry whose only source is a mockSource
+func newMockRegistry(found func(kind sources.Kind, gitRef string) bool) *sources.Registry {
+	return sources.NewRegistry(&mockSource{found: found})
 }
 
-// Mock tags handler that simulates a 404 (to trigger commit fallback)
-func mockTagsHandler404(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotFound)
-	if _, err := fmt.Fprint(w, `Not Found`); err != nil {
-		log.Printf("Error writing response: %v", err)
+// mockBody returns the response body served when gitRef is resolved as kind by mockSource
+func mockBody(kind sources.Kind, gitRef string) string {
+	body, err := json.Marshal(sources.StandardizedOutput{
+		Latest:  &sources.StandardizedEntity{Ref: "latest", Message: string(kind)},
+		Current: &sources.StandardizedEntity{Ref: gitRef, Message: string(kind)},
+	})
+	if err != nil {
+		log.Fatalf("Error encoding mock body: %v", err)
 	}
+	return string(body) + "\n"
 }
 
 func TestUnifiedHandlerWithCache(t *testing.T) {
@@ -64,8 +64,8 @@ func TestUnifiedHandlerWithCache(t *testing.T) {
 		gitRef           string
 		expectedStatus   int
 		expectedBody     string
-		use404Releases   bool // Whether to simulate a 404 response from ReleasesHandler
-		use404Tags       bool // Whether to simulate a 404 response from TagsHandler
+		use404Releases   bool // Whether to simulate the gitRef not being found as a release
+		use404Tags       bool // Whether to simulate the gitRef not being found as a tag
 		expectedCacheKey string
 	}{
 		{
@@ -73,7 +73,7 @@ func TestUnifiedHandlerWithCache(t *testing.T) {
 			repo:           "test/repo",
 			gitRef:         "v1.0.0",
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"handler": "releases"}`,
+			expectedBody:   mockBody(sources.KindRelease, "v1.0.0"),
 			use404Releases: false,
 			use404Tags:     false,
 		},
@@ -82,7 +82,7 @@ func TestUnifiedHandlerWithCache(t *testing.T) {
 			repo:           "test/repo",
 			gitRef:         "v2.0.0",
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"handler": "tags"}`,
+			expectedBody:   mockBody(sources.KindTag, "v2.0.0"),
 			use404Releases: true,
 			use404Tags:     false,
 		},
@@ -91,7 +91,7 @@ func TestUnifiedHandlerWithCache(t *testing.T) {
 			repo:           "test/repo",
 			gitRef:         "abc1234",
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"handler": "commits"}`,
+			expectedBody:   mockBody(sources.KindCommit, "abc1234"),
 			use404Releases: true,
 			use404Tags:     true,
 		},
@@ -100,16 +100,16 @@ func TestUnifiedHandlerWithCache(t *testing.T) {
 			repo:           "test/repo",
 			gitRef:         "abcdef1234567890abcdef1234567890abcdef12",
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"handler": "commits"}`,
+			expectedBody:   mockBody(sources.KindCommit, "abcdef1234567890abcdef1234567890abcdef12"),
 			use404Releases: true,
 			use404Tags:     true,
 		},
 		{
-			name:           "Invalid gitRef format (should still be processed by ReleasesHandler)",
+			name:           "Invalid gitRef format (should still be resolved as a release)",
 			repo:           "test/repo",
 			gitRef:         "not-a-commit-or-tag",
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"handler": "releases"}`,
+			expectedBody:   mockBody(sources.KindRelease, "not-a-commit-or-tag"),
 			use404Releases: false,
 			use404Tags:     false,
 		},
@@ -133,24 +133,21 @@ func TestUnifiedHandlerWithCache(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Logf("Running test: %s", tt.name)
 
-			// Mock dependencies based on whether we want a 404 for releases
-			releaseHandler := mockReleasesHandler
-			if tt.use404Releases {
-				releaseHandler = mockReleasesHandler404
-			}
-
-			// Mock dependencies based on whether we want a 404 for tags
-			tagsHandler := mockTagsHandler
-			if tt.use404Tags {
-				tagsHandler = mockTagsHandler404
-			}
+			// Mock a source that only finds the gitRef as the kinds that should not 404
+			registry := newMockRegistry(func(kind sources.Kind, gitRef string) bool {
+				switch kind {
+				case sources.KindRelease:
+					return !tt.use404Releases
+				case sources.KindTag:
+					return !tt.use404Tags
+				}
+				return true
+			})
 
-			// Initialize dependencies with the selected handlers
+			// Initialize dependencies with the mocked source
 			deps := &HandlerDeps{
-				CommitsHandler:  mockCommitsHandler,
-				ReleasesHandler: releaseHandler,
-				TagsHandler:     tagsHandler,
-				cache:           cache,
+				Sources: registry,
+				cache:   cache,
 				config: cacheConfiguration{
 					SuccessCacheDuration: 24 * time.Hour,
 					ErrorCacheDuration:   1 * time.Hour,
@@ -198,10 +195,8 @@ func TestUnifiedHandlerWithCache(t *testing.T) {
 
 		// Initialize a fresh HandlerDeps struct for this test
 		deps := &HandlerDeps{
-			CommitsHandler:  mockCommitsHandler,
-			ReleasesHandler: mockReleasesHandler,
-			TagsHandler:     mockTagsHandler,
-			cache:           cache,
+			Sources: newMockRegistry(func(sources.Kind, string) bool { return true }),
+			cache:   cache,
 			config: cacheConfiguration{
 				SuccessCacheDuration: 24 * time.Hour,
 				ErrorCacheDuration:   1 * time.Hour,
@@ -252,10 +247,8 @@ func TestUnifiedHandlerWithMetadataSuffix(t *testing.T) {
 	assert.NoError(t, err, "Failed to initialize cache")
 
 	deps := &HandlerDeps{
-		CommitsHandler:  mockCommitsHandler,
-		ReleasesHandler: mockReleasesHandler,
-		TagsHandler:     mockTagsHandler,
-		cache:           cache,
+		Sources: newMockRegistry(func(sources.Kind, string) bool { return true }),
+		cache:   cache,
 		config: cacheConfiguration{
 			SuccessCacheDuration: 24 * time.Hour,
 			ErrorCacheDuration:   1 * time.Hour,
@@ -277,7 +270,7 @@ func TestUnifiedHandlerWithMetadataSuffix(t *testing.T) {
 	handler.ServeHTTP(rr1, req1)
 
 	assert.Equal(t, http.StatusOK, rr1.Code)
-	assert.Equal(t, `{"handler": "releases"}`, rr1.Body.String())
+	assert.Equal(t, mockBody(sources.KindRelease, baseGitRef), rr1.Body.String())
 
 	// Verify cache key uses base gitRef (without metadata)
 	expectedCacheKey := fmt.Sprintf("%s:%s", repo, baseGitRef)
@@ -296,7 +289,7 @@ func TestUnifiedHandlerWithMetadataSuffix(t *testing.T) {
 
 	// Should get same cached response
 	assert.Equal(t, http.StatusOK, rr2.Code)
-	assert.Equal(t, `{"handler": "releases"}`, rr2.Body.String())
+	assert.Equal(t, mockBody(sources.KindRelease, baseGitRef), rr2.Body.String())
 	assert.Equal(t, string(cachedData.Body), rr2.Body.String(), "Different metadata suffixes should share cache")
 }
 
@@ -315,73 +308,43 @@ func TestUnifiedHandlerTagFallback(t *testing.T) {
 		{
 			name:           "Release exists - serve from releases",
 			gitRef:         "v1.0.0-release",
-			expectedBody:   `{"handler": "releases"}`,
+			expectedBody:   mockBody(sources.KindRelease, "v1.0.0-release"),
 			expectedStatus: http.StatusOK,
-			description:    "Should be served from ReleasesHandler",
+			description:    "Should be resolved as a release",
 		},
 		{
 			name:           "Release 404, tag exists - serve from tags",
 			gitRef:         "v1.0.0-tag",
-			expectedBody:   `{"handler": "tags"}`,
+			expectedBody:   mockBody(sources.KindTag, "v1.0.0-tag"),
 			expectedStatus: http.StatusOK,
-			description:    "Should fall back to TagsHandler",
+			description:    "Should fall back to a tag",
 		},
 		{
 			name:           "Release and tag 404, commit exists - serve from commits",
 			gitRef:         "abc123commit",
-			expectedBody:   `{"handler": "commits"}`,
+			expectedBody:   mockBody(sources.KindCommit, "abc123commit"),
 			expectedStatus: http.StatusOK,
-			description:    "Should fall back to CommitsHandler",
+			description:    "Should fall back to a commit",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Custom release handler that only succeeds for "-release" suffix
-			releaseHandler := func(w http.ResponseWriter, r *http.Request) {
-				gitRef := r.URL.Query().Get("gitRef")
-				if len(gitRef) > 8 && gitRef[len(gitRef)-8:] == "-release" {
-					w.WriteHeader(http.StatusOK)
-					if _, err := fmt.Fprint(w, `{"handler": "releases"}`); err != nil {
-						log.Printf("Error writing response: %v", err)
-					}
-				} else {
-					w.WriteHeader(http.StatusNotFound)
-					if _, err := fmt.Fprint(w, `Not Found`); err != nil {
-						log.Printf("Error writing response: %v", err)
-					}
-				}
-			}
-
-			// Custom tags handler that only succeeds for "-tag" suffix
-			tagsHandler := func(w http.ResponseWriter, r *http.Request) {
-				gitRef := r.URL.Query().Get("gitRef")
-				if len(gitRef) > 4 && gitRef[len(gitRef)-4:] == "-tag" {
-					w.WriteHeader(http.StatusOK)
-					if _, err := fmt.Fprint(w, `{"handler": "tags"}`); err != nil {
-						log.Printf("Error writing response: %v", err)
-					}
-				} else {
-					w.WriteHeader(http.StatusNotFound)
-					if _, err := fmt.Fprint(w, `Not Found`); err != nil {
-						log.Printf("Error writing response: %v", err)
-					}
-				}
-			}
-
-			// Custom commits handler that only succeeds for "commit" substring
-			commitsHandler := func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusOK)
-				if _, err := fmt.Fprint(w, `{"handler": "commits"}`); err != nil {
-					log.Printf("Error writing response: %v", err)
+			// Releases only resolve for the "-release" suffix, tags only for the "-tag" suffix
+			// and everything else resolves as a commit
+			registry := newMockRegistry(func(kind sources.Kind, gitRef string) bool {
+				switch kind {
+				case sources.KindRelease:
+					return strings.HasSuffix(gitRef, "-release")
+				case sources.KindTag:
+					return strings.HasSuffix(gitRef, "-tag")
 				}
-			}
+				return true
+			})
 
 			deps := &HandlerDeps{
-				CommitsHandler:  commitsHandler,
-				ReleasesHandler: releaseHandler,
-				TagsHandler:     tagsHandler,
-				cache:           cache,
+				Sources: registry,
+				cache:   cache,
 				config: cacheConfiguration{
 					SuccessCacheDuration: 24 * time.Hour,
 					ErrorCacheDuration:   1 * time.Hour,
@@ -500,3 +463,411 @@ func TestCacheConfigurationEnvVars(t *testing.T) {
 		})
 	}
 }
+
+// TestUnifiedHandlerErrors verifies how lookup failures are reported
+func TestUnifiedHandlerErrors(t *testing.T) {
+	cache, err := lru.NewWithEvict[string, CachedResponse](10, onEvict)
+	assert.NoError(t, err, "Failed to initialize cache")
+
+	deps := &HandlerDeps{
+		Sources: sources.NewRegistry(nil),
+		cache:   cache,
+		config: cacheConfiguration{
+			SuccessCacheDuration: 24 * time.Hour,
+			ErrorCacheDuration:   1 * time.Hour,
+		},
+	}
+	deps.Sources.RegisterPrefix("test", &mockSource{found: func(sources.Kind, string) bool { return false }})
+
+	tests := []struct {
+		name           string
+		repo           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Reference not found as any kind",
+			repo:           "test/repo",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"reference not found: commit v9.9.9"}` + "\n",
+		},
+		{
+			name:           "Repository without a source",
+			repo:           "other/repo",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "No source configured for repository 'other/repo'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/references", nil)
+			q := req.URL.Query()
+			q.Add("repo", tt.repo)
+			q.Add("gitRef", "v9.9.9")
+			req.URL.RawQuery = q.Encode()
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(deps.UnifiedHandler)
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+// TestUnifiedHandlerTagScope verifies that tags are scoped to the release stream of the application
+func TestUnifiedHandlerTagScope(t *testing.T) {
+	cache, err := lru.NewWithEvict[string, CachedResponse](10, onEvict)
+	assert.NoError(t, err, "Failed to initialize cache")
+
+	// Only the tags of the api service exist as releases
+	registry := newMockRegistry(func(kind sources.Kind, gitRef string) bool {
+		return kind == sources.KindRelease && strings.HasPrefix(gitRef, "api/")
+	})
+	deps := &HandlerDeps{
+		Sources: registry,
+		Config: &Config{Applications: map[string]ApplicationConfig{
+			"argocd:api": {TagScope: sources.TagScope{Prefix: "api/"}},
+		}},
+		cache: cache,
+		config: cacheConfiguration{
+			SuccessCacheDuration: 24 * time.Hour,
+			ErrorCacheDuration:   1 * time.Hour,
+		},
+	}
+
+	tests := []struct {
+		name           string
+		application    string
+		params         map[string]string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Prefix configured for the application",
+			application:    "argocd:api",
+			expectedStatus: http.StatusOK,
+			expectedBody:   mockBody(sources.KindRelease, "api/v1.2.3"),
+		},
+		{
+			name:           "Prefix from the query parameter",
+			params:         map[string]string{"tagPrefix": "api/"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   mockBody(sources.KindRelease, "api/v1.2.3"),
+		},
+		{
+			name:           "Query parameter overrides the application",
+			application:    "argocd:api",
+			params:         map[string]string{"tagPrefix": "worker/"},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"reference not found: commit v1.2.3"}` + "\n",
+		},
+		{
+			name:           "Invalid pattern",
+			params:         map[string]string{"tagPattern": "api/("},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: "Invalid 'tagPattern' query parameter: " +
+				"error parsing regexp: missing closing ): `api/(`\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/references", nil)
+			req.Header.Set("Argocd-Application-Name", tt.application)
+			q := req.URL.Query()
+			q.Add("repo", "test/monorepo")
+			q.Add("gitRef", "v1.2.3")
+			for key, value := range tt.params {
+				q.Add(key, value)
+			}
+			req.URL.RawQuery = q.Encode()
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(deps.UnifiedHandler)
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+// branchSource is a mockSource whose latest reference is named after the branch it tracks
+type branchSource struct {
+	mockSource
+}
+
+func (b *branchSource) ResolveLatest(ctx context.Context, q sources.Query) (*sources.StandardizedEntity, error) {
+	return &sources.StandardizedEntity{Ref: q.Branch}, nil
+}
+
+// TestUnifiedHandlerBranch verifies which branch commit deployments track
+func TestUnifiedHandlerBranch(t *testing.T) {
+	tests := []struct {
+		name          string
+		repo          string
+		param         string
+		defaultBranch string
+		expected      string
+	}{
+		{name: "Default branch of the repository", repo: "test/repo", expected: ""},
+		{name: "Default ref of the deployment", repo: "test/repo", defaultBranch: "main", expected: "main"},
+		{name: "Configured for the repository", repo: "test/release", defaultBranch: "main", expected: "release"},
+		{name: "Query parameter", repo: "test/release", param: "production", defaultBranch: "main", expected: "production"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := lru.NewWithEvict[string, CachedResponse](10, onEvict)
+			assert.NoError(t, err, "Failed to initialize cache")
+
+			deps := &HandlerDeps{
+				Sources: sources.NewRegistry(&branchSource{mockSource{
+					found: func(kind sources.Kind, gitRef string) bool { return kind == sources.KindCommit },
+				}}),
+				Config: &Config{Repositories: map[string]RepositoryConfig{
+					"test/release": {Branch: "release"},
+				}},
+				DefaultBranch: tt.defaultBranch,
+				cache:         cache,
+				config: cacheConfiguration{
+					SuccessCacheDuration: 24 * time.Hour,
+					ErrorCacheDuration:   1 * time.Hour,
+				},
+			}
+
+			req := httptest.NewRequest("GET", "/api/references", nil)
+			q := req.URL.Query()
+			q.Add("repo", tt.repo)
+			q.Add("gitRef", "abc1234")
+			if tt.param != "" {
+				q.Add("branch", tt.param)
+			}
+			req.URL.RawQuery = q.Encode()
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(deps.UnifiedHandler)
+			handler.ServeHTTP(rr, req)
+
+			var output sources.StandardizedOutput
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &output))
+			assert.Equal(t, tt.expected, output.Latest.Ref)
+		})
+	}
+}
+
+// expandingSource is a mockSource expanding abbreviated SHAs of a single commit and reporting others as ambiguous,
+// counting its expansions
+type expandingSource struct {
+	mockSource
+	expanded int
+}
+
+const expandedSHA = "abc1234def567890abc1234def567890abc1234d"
+
+func (e *expandingSource) ExpandCommitSHA(ctx context.Context, repo, prefix string) (string, error) {
+	e.expanded++
+	if strings.HasPrefix(expandedSHA, prefix) {
+		return expandedSHA, nil
+	}
+	return "", &sources.AmbiguousRefError{Prefix: prefix, Candidates: []string{prefix + "0", prefix + "1"}}
+}
+
+// TestUnifiedHandlerShortSHA verifies that abbreviated SHAs are looked up as the commit they abbreviate, and only
+// expanded once tried as commits
+func TestUnifiedHandlerShortSHA(t *testing.T) {
+	source := &expandingSource{mockSource: mockSource{
+		found: func(kind sources.Kind, gitRef string) bool {
+			return kind == sources.KindCommit && gitRef == expandedSHA || kind == sources.KindTag && gitRef == "1234567"
+		},
+	}}
+	deps := newTestDeps(t, source)
+	handler := http.HandlerFunc(deps.UnifiedHandler)
+
+	lookup := func(gitRef string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/references?repo=test/repo&gitRef="+gitRef, nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, gitRef := range []string{"abc1234", "ABC1234DEF", "abc1234de", expandedSHA} {
+		rr := lookup(gitRef)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, mockBody(sources.KindCommit, expandedSHA), rr.Body.String())
+	}
+	assert.Equal(t, 3, source.expanded, "full SHAs should not be expanded")
+
+	// Abbreviations and the full SHA share the response cached under the full SHA, and expansions are cached
+	assert.True(t, deps.cache.Contains("test/repo:"+expandedSHA))
+	assert.False(t, deps.cache.Contains("test/repo:abc1234"))
+	assert.False(t, deps.cache.Contains("test/repo:ABC1234DEF"))
+	assert.Equal(t, http.StatusOK, lookup("abc1234").Code)
+	assert.Equal(t, 3, source.expanded)
+
+	// Tags named like SHAs are found as tags, without expansion
+	rr := lookup("1234567")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, mockBody(sources.KindTag, "1234567"), rr.Body.String())
+	assert.Equal(t, 3, source.expanded, "tags should not be expanded")
+
+	rr = lookup("fedcba9")
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	var response ErrorResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, []string{"fedcba90", "fedcba91"}, response.Candidates)
+	assert.Contains(t, response.Error, "ambiguous gitRef")
+}
+
+// TestUnifiedHandlerRefRules verifies that configured rules extract the gitRef from image tags
+func TestUnifiedHandlerRefRules(t *testing.T) {
+	deps := newTestDeps(t, &mockSource{found: func(kind sources.Kind, gitRef string) bool {
+		// Only 1.4.1 was released, and only its commit and that of 1.4.2 exist
+		return gitRef == "1.4.1" || kind == sources.KindCommit && (gitRef == "abcdef0" || gitRef == "1234567")
+	}})
+	deps.Config = &Config{RefRules: []sources.RefRule{
+		{Name: "version-sha", Pattern: sources.MustParsePattern(`^(?P<tag>\d+\.\d+\.\d+)-(?P<sha>[0-9a-f]{7,40})$`)},
+		{Name: "branch-sha-date", Pattern: sources.MustParsePattern(`^[a-z]+-(?P<sha>[0-9a-f]{7,40})-\d{8}$`)},
+	}}
+	handler := http.HandlerFunc(deps.UnifiedHandler)
+
+	tests := []struct {
+		name           string
+		imageTag       string
+		wantKind       sources.Kind
+		wantRef        string
+		wantExtraction *sources.Extraction
+	}{
+		{
+			name:           "Released tag",
+			imageTag:       "1.4.1-1234567",
+			wantKind:       sources.KindRelease,
+			wantRef:        "1.4.1",
+			wantExtraction: &sources.Extraction{Rule: "version-sha", Tag: "1.4.1", SHA: "1234567"},
+		},
+		{
+			name:           "Missing tag falls back to its commit",
+			imageTag:       "1.4.2-abcdef0",
+			wantKind:       sources.KindCommit,
+			wantRef:        "abcdef0",
+			wantExtraction: &sources.Extraction{Rule: "version-sha", Tag: "1.4.2", SHA: "abcdef0"},
+		},
+		{
+			name:           "SHA only",
+			imageTag:       "main-abcdef0-20240101",
+			wantKind:       sources.KindCommit,
+			wantRef:        "abcdef0",
+			wantExtraction: &sources.Extraction{Rule: "branch-sha-date", SHA: "abcdef0"},
+		},
+		{
+			name:     "Default convention when no rule matches",
+			imageTag: "1.4.1--stage",
+			wantKind: sources.KindRelease,
+			wantRef:  "1.4.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/references?repo=test/repo&gitRef="+tt.imageTag, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var output sources.StandardizedOutput
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &output))
+			assert.Equal(t, tt.wantRef, output.Current.Ref)
+			assert.Equal(t, string(tt.wantKind), output.Current.Message)
+			assert.Equal(t, tt.wantExtraction, output.Extraction)
+		})
+	}
+}
+
+// recordingSource is a mockSource recording the kinds each gitRef was tried as
+type recordingSource struct {
+	mockSource
+	tried []sources.Kind
+}
+
+func (s *recordingSource) ResolveCurrent(ctx context.Context, q sources.Query) (*sources.StandardizedEntity, error) {
+	s.tried = append(s.tried, q.Kind)
+	return s.mockSource.ResolveCurrent(ctx, q)
+}
+
+func TestUnifiedHandlerResolutionOrder(t *testing.T) {
+	const sha = "dd295fd679"
+
+	tests := []struct {
+		name       string
+		config     *Config
+		gitRef     string
+		kind       string
+		wantStatus int
+		wantTried  []sources.Kind
+	}{
+		{
+			name:       "Default order",
+			gitRef:     "v1.0.0",
+			wantStatus: http.StatusOK,
+			wantTried:  []sources.Kind{sources.KindRelease, sources.KindTag},
+		},
+		{
+			name:       "Obvious SHA tried as a commit first",
+			gitRef:     sha,
+			wantStatus: http.StatusOK,
+			wantTried:  []sources.Kind{sources.KindCommit},
+		},
+		{
+			name:       "Configured order",
+			config:     &Config{ResolutionOrder: sources.ResolutionOrder{sources.KindTag, sources.KindCommit}},
+			gitRef:     "v1.0.0",
+			wantStatus: http.StatusOK,
+			wantTried:  []sources.Kind{sources.KindTag},
+		},
+		{
+			name:       "Requested kind",
+			gitRef:     "v1.0.0",
+			kind:       "commit",
+			wantStatus: http.StatusNotFound,
+			wantTried:  []sources.Kind{sources.KindCommit},
+		},
+		{
+			name:       "Requested order is followed for SHAs",
+			gitRef:     sha,
+			kind:       "release,commit",
+			wantStatus: http.StatusOK,
+			wantTried:  []sources.Kind{sources.KindRelease, sources.KindCommit},
+		},
+		{
+			name:       "Invalid kind",
+			gitRef:     "v1.0.0",
+			kind:       "branch",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &recordingSource{mockSource: mockSource{found: func(kind sources.Kind, gitRef string) bool {
+				return kind == sources.KindTag && gitRef == "v1.0.0" || kind == sources.KindCommit && gitRef == sha
+			}}}
+			deps := newTestDeps(t, source)
+			deps.Config = tt.config
+
+			params := url.Values{"repo": {"test/repo"}, "gitRef": {tt.gitRef}}
+			if tt.kind != "" {
+				params.Set("kind", tt.kind)
+			}
+			req := httptest.NewRequest("GET", "/api/references?"+params.Encode(), nil)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(deps.UnifiedHandler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+			assert.Equal(t, tt.wantTried, source.tried)
+		})
+	}
+}
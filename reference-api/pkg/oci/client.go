@@ -0,0 +1,257 @@
+package oci
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Labels set on images by their build, as defined by the OCI image spec
+const (
+	LabelSource   = "org.opencontainers.image.source"   // URL of the repository the image was built from
+	LabelRevision = "org.opencontainers.image.revision" // Commit SHA the image was built from
+)
+
+// maxResponseSize bounds the manifests, config blobs and tokens read from registries, which are far smaller
+const maxResponseSize = 4 << 20
+
+// Media types of the manifests and indexes the client understands
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+// manifest is an image manifest or, when Manifests is set, an index of the manifests of each platform
+type manifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform *struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// imageConfig is the subset of an image config blob holding its labels
+type imageConfig struct {
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// Client reads images from registries, anonymously or with the credentials of their registry.
+// Only the registries it has credentials for or is allowed to read anonymously are contacted, since image references
+// come from requests.
+type Client struct {
+	httpClient  *http.Client
+	credentials Credentials
+	registries  map[string]bool
+}
+
+// NewClient creates a Client reading images from the registries it has credentials for and from the given
+// registries, such as "ghcr.io" or "docker.io". httpClient may be nil to use http.DefaultClient.
+func NewClient(httpClient *http.Client, credentials Credentials, registries []string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	allowed := make(map[string]bool, len(credentials)+len(registries))
+	for registry := range credentials {
+		allowed[registry] = true
+	}
+	for _, registry := range registries {
+		allowed[registryHost(registry)] = true
+	}
+	return &Client{httpClient: httpClient, credentials: credentials, registries: allowed}
+}
+
+// Labels returns the labels of the image ref, as set in its config blob.
+// For multi-platform images the labels of the linux/amd64 image are returned, else those of the first one.
+func (c *Client) Labels(ctx context.Context, ref Reference) (map[string]string, error) {
+	if err := ref.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", sources.ErrInvalidRef, err)
+	}
+	if !c.registries[ref.Registry] {
+		return nil, fmt.Errorf("%w: registry %s is not allowed", sources.ErrInvalidRef, ref.Registry)
+	}
+	s := &session{client: c, ref: ref}
+
+	var m manifest
+	if err := s.getJSON(ctx, "manifests/"+ref.manifestRef(), strings.Join(manifestMediaTypes, ", "), &m); err != nil {
+		return nil, err
+	}
+	if len(m.Manifests) > 0 {
+		digest := m.Manifests[0].Digest
+		for _, platform := range m.Manifests {
+			if platform.Platform != nil && platform.Platform.OS == "linux" && platform.Platform.Architecture == "amd64" {
+				digest = platform.Digest
+				break
+			}
+		}
+		m = manifest{}
+		if err := s.getJSON(ctx, "manifests/"+digest, strings.Join(manifestMediaTypes, ", "), &m); err != nil {
+			return nil, err
+		}
+	}
+	if m.Config.Digest == "" {
+		return nil, fmt.Errorf("manifest of image %s has no config", ref.Repository)
+	}
+
+	var config imageConfig
+	if err := s.getJSON(ctx, "blobs/"+m.Config.Digest, "application/json", &config); err != nil {
+		return nil, err
+	}
+	return config.Config.Labels, nil
+}
+
+// session holds the authorization obtained from a registry for the requests about a single image
+type session struct {
+	client        *Client
+	ref           Reference
+	authorization string
+}
+
+// getJSON fetches the path of the image repository and decodes the JSON response into out,
+// authenticating as the registry asks when it refuses anonymous requests
+func (s *session) getJSON(ctx context.Context, path, accept string, out any) error {
+	endpoint := fmt.Sprintf("https://%s/v2/%s/%s", s.ref.host(), s.ref.Repository, path)
+
+	resp, err := s.get(ctx, endpoint, accept)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && s.authorization == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+		if s.authorization, err = s.authorize(ctx, challenge); err != nil {
+			return err
+		}
+		if resp, err = s.get(ctx, endpoint, accept); err != nil {
+			return err
+		}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: image %s/%s not found", sources.ErrNotFound, s.ref.Registry, s.ref.Repository)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry error: %s returned %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(out)
+}
+
+// get performs a GET request with the authorization of the session
+func (s *session) get(ctx context.Context, endpoint, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if s.authorization != "" {
+		req.Header.Set("Authorization", s.authorization)
+	}
+
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("registry error: %v", err)
+	}
+	return resp, nil
+}
+
+// authorize answers the WWW-Authenticate challenge of the registry, returning the Authorization header to send
+func (s *session) authorize(ctx context.Context, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	credential, hasCredential := s.client.credentials.lookup(s.ref.Registry)
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if !hasCredential {
+			return "", fmt.Errorf("registry %s requires credentials", s.ref.Registry)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credential.Username+":"+credential.Password)), nil
+	case "bearer":
+		token, err := s.fetchToken(ctx, params, credential, hasCredential)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	}
+	return "", fmt.Errorf("registry %s asks for unsupported authentication %q", s.ref.Registry, challenge)
+}
+
+// fetchToken requests a pull token for the image repository from the token service named by the challenge
+func (s *session) fetchToken(
+	ctx context.Context, params map[string]string, credential Credential, hasCredential bool,
+) (string, error) {
+	// Credentials are only ever sent to token services over HTTPS
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" || realm.Scheme != "https" {
+		return "", fmt.Errorf("registry %s sent an invalid token realm %q", s.ref.Registry, params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", s.ref.Repository))
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if hasCredential {
+		req.SetBasicAuth(credential.Username, credential.Password)
+	}
+
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("registry token error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return "", fmt.Errorf("registry token error: %s returned %s", realm.Host, resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid registry token response: %v", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// parseChallenge splits a WWW-Authenticate header such as `Bearer realm="...",service="..."` into its scheme and
+// parameters
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, ", "), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return scheme, params
+}
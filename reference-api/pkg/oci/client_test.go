@@ -0,0 +1,99 @@
+package oci
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/oci/ocitest"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLabels = map[string]string{
+	LabelSource:   "https://github.com/mozilla/app",
+	LabelRevision: "abc123def4567890abc123def4567890abc123de",
+}
+
+func TestClientLabels(t *testing.T) {
+	registry := ocitest.NewRegistry(t)
+	digest := registry.Push("mozilla/app", "v1.2.3", testLabels)
+	registry.PushIndex("mozilla/app", "multi", map[string]map[string]string{
+		"linux/amd64": testLabels,
+		"linux/arm64": {LabelRevision: "arm64"},
+	})
+	client := NewClient(registry.Client(), nil, []string{registry.Host()})
+
+	tests := []struct {
+		name string
+		ref  Reference
+	}{
+		{name: "By tag", ref: Reference{Registry: registry.Host(), Repository: "mozilla/app", Tag: "v1.2.3"}},
+		{name: "By digest", ref: Reference{Registry: registry.Host(), Repository: "mozilla/app", Digest: digest}},
+		{name: "Multi-platform", ref: Reference{Registry: registry.Host(), Repository: "mozilla/app", Tag: "multi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, err := client.Labels(context.Background(), tt.ref)
+			assert.NoError(t, err)
+			assert.Equal(t, testLabels, labels)
+		})
+	}
+
+	_, err := client.Labels(context.Background(), Reference{Registry: registry.Host(), Repository: "mozilla/app", Tag: "v9"})
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestClientLabelsWithCredentials(t *testing.T) {
+	registry := ocitest.NewRegistry(t)
+	registry.Push("mozilla/private", "v1.0.0", testLabels)
+	registry.RequireAuth("bot", "token")
+	ref := Reference{Registry: registry.Host(), Repository: "mozilla/private", Tag: "v1.0.0"}
+
+	_, err := NewClient(registry.Client(), nil, []string{registry.Host()}).Labels(context.Background(), ref)
+	assert.ErrorContains(t, err, "registry token error")
+
+	client := NewClient(registry.Client(), Credentials{registry.Host(): {Username: "bot", Password: "token"}}, nil)
+	labels, err := client.Labels(context.Background(), ref)
+	assert.NoError(t, err)
+	assert.Equal(t, testLabels, labels)
+}
+
+func TestClientLabelsUnlistedRegistry(t *testing.T) {
+	registry := ocitest.NewRegistry(t)
+	registry.Push("mozilla/app", "v1.2.3", testLabels)
+	ref := Reference{Registry: registry.Host(), Repository: "mozilla/app", Tag: "v1.2.3"}
+
+	_, err := NewClient(registry.Client(), nil, []string{"ghcr.io"}).Labels(context.Background(), ref)
+	assert.ErrorIs(t, err, sources.ErrInvalidRef)
+	assert.Empty(t, registry.Requests())
+}
+
+func TestClientLabelsInvalidReference(t *testing.T) {
+	registry := ocitest.NewRegistry(t)
+	client := NewClient(registry.Client(), nil, []string{registry.Host()})
+
+	for _, ref := range []Reference{
+		{Registry: registry.Host(), Repository: "mozilla/../admin", Tag: "v1"},
+		{Registry: registry.Host(), Repository: "mozilla/app", Tag: "v1?x=1"},
+		{Registry: registry.Host(), Repository: "mozilla/app", Digest: "sha256:../blobs"},
+	} {
+		_, err := client.Labels(context.Background(), ref)
+		assert.ErrorIs(t, err, sources.ErrInvalidRef, ref)
+	}
+	assert.Empty(t, registry.Requests())
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:a/b:pull"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://ghcr.io/token",
+		"service": "ghcr.io",
+		"scope":   "repository:a/b:pull",
+	}, params)
+
+	scheme, params = parseChallenge(`Basic realm=registry`)
+	assert.Equal(t, "Basic", scheme)
+	assert.Equal(t, map[string]string{"realm": "registry"}, params)
+}
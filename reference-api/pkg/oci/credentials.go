@@ -0,0 +1,80 @@
+package oci
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Credential authenticates pulls from a registry
+type Credential struct {
+	Username string
+	Password string
+}
+
+// Credentials holds the credential of each registry, keyed by registry host
+type Credentials map[string]Credential
+
+// dockerConfig is the format of .dockerconfigjson files, such as those of Kubernetes image pull secrets
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"` // Base64 encoded "<username>:<password>"
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+}
+
+// LoadDockerConfig reads the registry credentials of the .dockerconfigjson files at paths.
+// Credentials of later files win over those of earlier ones for the same registry.
+func LoadDockerConfig(paths ...string) (Credentials, error) {
+	credentials := Credentials{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pull secret: %v", err)
+		}
+
+		var config dockerConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse pull secret %s: %v", path, err)
+		}
+
+		for server, auth := range config.Auths {
+			credential := Credential{Username: auth.Username, Password: auth.Password}
+			if auth.Auth != "" {
+				decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+				if err != nil {
+					return nil, fmt.Errorf("invalid auth of %s in pull secret %s: %v", server, path, err)
+				}
+				credential.Username, credential.Password, _ = strings.Cut(string(decoded), ":")
+			}
+			credentials[registryHost(server)] = credential
+		}
+	}
+	return credentials, nil
+}
+
+// registryHost returns the registry host of a docker config server, which may be a URL such as
+// "https://index.docker.io/v1/"
+func registryHost(server string) string {
+	host := server
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host, _, _ = strings.Cut(host, "/")
+
+	switch host {
+	case "index.docker.io", dockerHubHost:
+		return dockerHub
+	}
+	return host
+}
+
+// lookup returns the credential of registry, if any
+func (c Credentials) lookup(registry string) (Credential, bool) {
+	credential, ok := c[registry]
+	return credential, ok
+}
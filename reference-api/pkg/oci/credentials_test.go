@@ -0,0 +1,34 @@
+package oci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDockerConfig(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	second := filepath.Join(dir, "second.json")
+	assert.NoError(t, os.WriteFile(first, []byte(`{"auths": {
+		"https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="},
+		"ghcr.io": {"username": "old", "password": "old"}
+	}}`), 0o600))
+	assert.NoError(t, os.WriteFile(second, []byte(`{"auths": {"ghcr.io": {"username": "bot", "password": "token"}}}`), 0o600))
+
+	credentials, err := LoadDockerConfig(first, second)
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{
+		"docker.io": {Username: "hub", Password: "secret"},
+		"ghcr.io":   {Username: "bot", Password: "token"},
+	}, credentials)
+
+	_, err = LoadDockerConfig(filepath.Join(dir, "missing.json"))
+	assert.ErrorContains(t, err, "failed to read pull secret")
+
+	assert.NoError(t, os.WriteFile(first, []byte(`{"auths": {"ghcr.io": {"auth": "not base64!"}}}`), 0o600))
+	_, err = LoadDockerConfig(first)
+	assert.ErrorContains(t, err, "invalid auth of ghcr.io")
+}
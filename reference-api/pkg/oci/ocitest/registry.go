@@ -0,0 +1,188 @@
+// Package ocitest provides an in-process stand-in of an OCI registry for tests
+package ocitest
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	indexMediaType    = "application/vnd.oci.image.index.v1+json"
+	testToken         = "test-pull-token"
+)
+
+// Registry serves images over the OCI distribution API, optionally behind a token service requiring credentials
+type Registry struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	username  string
+	password  string
+	manifests map[string]blob // Keyed by "<repository>:<tag or digest>"
+	blobs     map[string]blob // Keyed by "<repository>@<digest>"
+	requests  int
+}
+
+type blob struct {
+	mediaType string
+	content   []byte
+}
+
+// NewRegistry starts a Registry that is closed when the test ends
+func NewRegistry(t testing.TB) *Registry {
+	r := &Registry{manifests: map[string]blob{}, blobs: map[string]blob{}}
+	r.server = httptest.NewTLSServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// Host returns the host images of the registry are referenced by
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.server.URL, "https://")
+}
+
+// Client returns an HTTP client trusting the registry
+func (r *Registry) Client() *http.Client {
+	return r.server.Client()
+}
+
+// Requests returns the number of distribution API requests served
+func (r *Registry) Requests() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests
+}
+
+// RequireAuth makes pulls require a token issued for the given credentials
+func (r *Registry) RequireAuth(username, password string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.username, r.password = username, password
+}
+
+// Push stores an image with the given labels under tag, returning the digest of its manifest
+func (r *Registry) Push(repository, tag string, labels map[string]string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	digest := r.pushImage(repository, labels)
+	r.manifests[repository+":"+tag] = r.manifests[repository+":"+digest]
+	return digest
+}
+
+// PushIndex stores a multi-platform image under tag, with the labels of each platform keyed as "<os>/<arch>".
+// It returns the digest of its index.
+func (r *Registry) PushIndex(repository, tag string, labels map[string]map[string]string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	platforms := make([]string, 0, len(labels))
+	for platform := range labels {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	type entry struct {
+		MediaType string            `json:"mediaType"`
+		Digest    string            `json:"digest"`
+		Platform  map[string]string `json:"platform"`
+	}
+	var entries []entry
+	for _, platform := range platforms {
+		os, arch, _ := strings.Cut(platform, "/")
+		entries = append(entries, entry{
+			MediaType: manifestMediaType,
+			Digest:    r.pushImage(repository, labels[platform]),
+			Platform:  map[string]string{"os": os, "architecture": arch},
+		})
+	}
+
+	index := mustMarshal(map[string]any{"schemaVersion": 2, "mediaType": indexMediaType, "manifests": entries})
+	digest := digestOf(index)
+	r.manifests[repository+":"+digest] = blob{mediaType: indexMediaType, content: index}
+	r.manifests[repository+":"+tag] = r.manifests[repository+":"+digest]
+	return digest
+}
+
+// pushImage stores the config and manifest of a single-platform image, returning the digest of its manifest
+func (r *Registry) pushImage(repository string, labels map[string]string) string {
+	config := mustMarshal(map[string]any{"architecture": "amd64", "os": "linux", "config": map[string]any{"Labels": labels}})
+	configDigest := digestOf(config)
+	r.blobs[repository+"@"+configDigest] = blob{mediaType: "application/vnd.oci.image.config.v1+json", content: config}
+
+	manifest := mustMarshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     manifestMediaType,
+		"config":        map[string]any{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": configDigest},
+		"layers":        []any{},
+	})
+	digest := digestOf(manifest)
+	r.manifests[repository+":"+digest] = blob{mediaType: manifestMediaType, content: manifest}
+	return digest
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		r.serveToken(w, req)
+		return
+	}
+
+	r.requests++
+	if r.username != "" && req.Header.Get("Authorization") != "Bearer "+testToken {
+		w.Header().Set("WWW-Authenticate",
+			fmt.Sprintf(`Bearer realm="%s/token",service="ocitest"`, r.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// Paths are /v2/<repository>/manifests/<reference> or /v2/<repository>/blobs/<digest>
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	var found blob
+	var ok bool
+	if repository, reference, isManifest := strings.Cut(path, "/manifests/"); isManifest {
+		found, ok = r.manifests[repository+":"+reference]
+	} else if repository, digest, isBlob := strings.Cut(path, "/blobs/"); isBlob {
+		found, ok = r.blobs[repository+"@"+digest]
+	}
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `{"errors":[{"code":"MANIFEST_UNKNOWN"}]}`)
+		return
+	}
+
+	w.Header().Set("Content-Type", found.mediaType)
+	_, _ = w.Write(found.content)
+}
+
+// serveToken issues pull tokens to clients presenting the required credentials
+func (r *Registry) serveToken(w http.ResponseWriter, req *http.Request) {
+	username, password, _ := req.BasicAuth()
+	if username != r.username || password != r.password || !strings.HasSuffix(req.URL.Query().Get("scope"), ":pull") {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprintf(w, `{"token": %q}`, testToken)
+}
+
+func digestOf(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
+func mustMarshal(v any) []byte {
+	content, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
@@ -0,0 +1,95 @@
+// Package oci reads the labels of container images from registries implementing the OCI distribution API
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	dockerHub     = "docker.io"            // Registry of image references without a registry host
+	dockerHubHost = "registry-1.docker.io" // Host serving the distribution API of Docker Hub
+)
+
+// Grammar of the parts of references, as defined by the OCI distribution specification
+var (
+	registryRegex   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?(:[0-9]+)?$`)
+	repositoryRegex = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+	tagRegex        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+	digestRegex     = regexp.MustCompile(`^[a-z0-9]+([+._-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+)
+
+// Reference locates an image in a registry, such as "ghcr.io/mozilla/app:v1.2.3@sha256:..."
+type Reference struct {
+	Registry   string // Registry host, "docker.io" for Docker Hub
+	Repository string // Repository path within the registry
+	Tag        string // Tag of the image, may be empty when Digest is set
+	Digest     string // Digest of the manifest, such as "sha256:...", preferred over Tag when set
+}
+
+// ParseReference parses an image reference as found in the images of an Argo CD application
+func ParseReference(image string) (Reference, error) {
+	var ref Reference
+	name := image
+	if before, digest, ok := strings.Cut(name, "@"); ok {
+		name, ref.Digest = before, digest
+	}
+
+	// The tag follows the last colon of the last path segment, as earlier ones separate the registry port
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+		if ref.Tag == "" {
+			return Reference{}, fmt.Errorf("empty tag in image reference %q", image)
+		}
+	}
+
+	// The first path segment names the registry when it looks like a host
+	ref.Registry, ref.Repository = dockerHub, name
+	if host, path, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		ref.Registry, ref.Repository = host, path
+	}
+	if ref.Registry == dockerHub && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	if err := ref.Validate(); err != nil {
+		return Reference{}, fmt.Errorf("invalid image reference %q: %v", image, err)
+	}
+	return ref, nil
+}
+
+// Validate checks the parts of the reference against the grammar of the OCI distribution specification, so that
+// they cannot alter the URLs of the registry API they are put in
+func (r Reference) Validate() error {
+	switch {
+	case !registryRegex.MatchString(r.Registry):
+		return fmt.Errorf("invalid registry %q", r.Registry)
+	case !repositoryRegex.MatchString(r.Repository):
+		return fmt.Errorf("invalid repository %q", r.Repository)
+	case r.Tag != "" && !tagRegex.MatchString(r.Tag):
+		return fmt.Errorf("invalid tag %q", r.Tag)
+	case r.Digest != "" && !digestRegex.MatchString(r.Digest):
+		return fmt.Errorf("invalid digest %q", r.Digest)
+	}
+	return nil
+}
+
+// manifestRef returns the digest or tag the manifest of the image is fetched by
+func (r Reference) manifestRef() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	if r.Tag != "" {
+		return r.Tag
+	}
+	return "latest"
+}
+
+// host returns the host serving the distribution API of the registry
+func (r Reference) host() string {
+	if r.Registry == dockerHub {
+		return dockerHubHost
+	}
+	return r.Registry
+}
@@ -0,0 +1,66 @@
+package oci
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name    string
+		image   string
+		want    Reference
+		wantErr bool
+	}{
+		{
+			name:  "Registry, tag and digest",
+			image: "ghcr.io/mozilla/app:v1.2.3@" + digest,
+			want:  Reference{Registry: "ghcr.io", Repository: "mozilla/app", Tag: "v1.2.3", Digest: digest},
+		},
+		{
+			name:  "Registry with a port",
+			image: "localhost:5000/team/app:1.4.2-abcdef0",
+			want:  Reference{Registry: "localhost:5000", Repository: "team/app", Tag: "1.4.2-abcdef0"},
+		},
+		{
+			name:  "Digest only",
+			image: "us-docker.pkg.dev/project/images/app@" + digest,
+			want:  Reference{Registry: "us-docker.pkg.dev", Repository: "project/images/app", Digest: digest},
+		},
+		{
+			name:  "Docker Hub",
+			image: "mozilla/app:v1",
+			want:  Reference{Registry: "docker.io", Repository: "mozilla/app", Tag: "v1"},
+		},
+		{
+			name:  "Docker Hub official image",
+			image: "nginx",
+			want:  Reference{Registry: "docker.io", Repository: "library/nginx"},
+		},
+		{name: "Empty tag", image: "ghcr.io/mozilla/app:", wantErr: true},
+		{name: "Invalid digest", image: "ghcr.io/mozilla/app@0123", wantErr: true},
+		{name: "Digest that is not hexadecimal", image: "ghcr.io/mozilla/app@sha256:../../x", wantErr: true},
+		{name: "Empty", image: "", wantErr: true},
+		{name: "Parent path segment", image: "ghcr.io/mozilla/../app:v1", wantErr: true},
+		{name: "Query in the repository", image: "ghcr.io/mozilla/app?x=1", wantErr: true},
+		{name: "Fragment in the tag", image: "ghcr.io/mozilla/app:v1#x", wantErr: true},
+		{name: "Uppercase repository", image: "ghcr.io/Mozilla/app:v1", wantErr: true},
+		{name: "Tag too long", image: "ghcr.io/mozilla/app:" + strings.Repeat("v", 129), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReference(tt.image)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
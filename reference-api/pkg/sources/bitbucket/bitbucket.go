@@ -0,0 +1,96 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Flavor selects which Bitbucket product and REST API a Source talks to
+type Flavor string
+
+const (
+	// Cloud is bitbucket.org, addressed as "workspace/repo"
+	Cloud Flavor = "cloud"
+	// DataCenter is a self-hosted Bitbucket Data Center or Server, addressed as "PROJECT/repo"
+	DataCenter Flavor = "datacenter"
+)
+
+// DefaultCloudURL is the API base URL of Bitbucket Cloud
+const DefaultCloudURL = "https://api.bitbucket.org/2.0"
+
+// Commit is a commit as returned by either Bitbucket flavor
+type Commit struct {
+	Hash    string
+	Message string
+	Author  string
+	Date    time.Time
+	URL     string
+}
+
+// Tag is a tag as returned by either Bitbucket flavor.
+// Message, Tagger and Date are only set for annotated tags.
+type Tag struct {
+	Name    string
+	Message string
+	Tagger  string
+	Date    time.Time
+	Commit  *Commit
+	Kind    string // sources.TagAnnotated or sources.TagLightweight, empty when the flavor does not tell
+}
+
+// api is implemented by each Bitbucket flavor
+type api interface {
+	FetchTag(ctx context.Context, repo, name string) (*Tag, error)
+	FetchLatestTag(ctx context.Context, repo string) (*Tag, error)
+	ListTagNames(ctx context.Context, repo string) ([]string, error)
+	FetchCommit(ctx context.Context, repo, sha string) (*Commit, error)
+	FetchLatestCommit(ctx context.Context, repo, branch string) (*Commit, error)
+}
+
+// restClient performs authenticated GET requests against a Bitbucket REST API
+type restClient struct {
+	baseURL    string
+	username   string
+	token      string
+	httpClient *http.Client
+}
+
+// get decodes the JSON response of a GET request into out.
+// A 404 from Bitbucket is returned as an error wrapping sources.ErrNotFound.
+func (c *restClient) get(ctx context.Context, path string, query url.Values, out any) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	header := http.Header{}
+	switch {
+	case c.username != "" && c.token != "":
+		// App passwords are used together with the account username
+		credentials := base64.StdEncoding.EncodeToString([]byte(c.username + ":" + c.token))
+		header.Set("Authorization", "Basic "+credentials)
+	case c.token != "":
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	if err := sources.GetJSON(ctx, c.httpClient, endpoint, header, out); err != nil {
+		return fmt.Errorf("Bitbucket %w", err)
+	}
+	return nil
+}
+
+// pageSize is the number of items requested per page of a listing
+const pageSize = 100
+
+// splitRepo splits "workspace/repo" or "PROJECT/repo" into its escaped path segments
+func splitRepo(repo string) (string, string) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	return url.PathEscape(owner), url.PathEscape(repoName)
+}
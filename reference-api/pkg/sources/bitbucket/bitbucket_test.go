@@ -0,0 +1,269 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/sourcestest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	tagSHA  = "fedcba9876543210fedcba9876543210fedcba98"
+	headSHA = "abc123def4567890abc123def4567890abc123de"
+)
+
+// testAPI returns a stand-in of a Bitbucket API requiring the given Authorization header
+func testAPI(authorization string) sourcestest.API {
+	return sourcestest.API{Header: "Authorization", Authorization: authorization, NotFound: `{"type":"error"}`}
+}
+
+// cloudCommitJSON returns a Bitbucket Cloud commit response
+func cloudCommitJSON(sha, message, date string) string {
+	return fmt.Sprintf(`{
+		"hash": %q,
+		"date": %q,
+		"message": %q,
+		"author": {"raw": "Commit Author <author@example.com>", "user": {"nickname": "commitauthor"}},
+		"links": {"html": {"href": "https://bitbucket.org/team/app/commits/%s"}}
+	}`, sha, date, message, sha)
+}
+
+func TestCloudSource(t *testing.T) {
+	const repoBase = "/repositories/team/app"
+	annotatedTag := `{
+		"name": "v1.1.0",
+		"message": "Release 1.1.0",
+		"date": "2025-07-02T09:00:00+00:00",
+		"tagger": {"raw": "Release Manager <rm@example.com>", "user": {"nickname": "releasemanager"}},
+		"target": ` + cloudCommitJSON(tagSHA, "Tagged change", "2025-07-01T08:00:00+00:00") + `
+	}`
+	lightweightTag := `{
+		"name": "v1.0.0",
+		"message": null,
+		"date": null,
+		"tagger": null,
+		"target": ` + cloudCommitJSON(tagSHA, "Tagged change", "2025-07-01T08:00:00+00:00") + `
+	}`
+
+	server := testAPI("Basic dXNlcjphcHAtcGFzc3dvcmQ=").ServeJSON(t, map[string]string{
+		repoBase:                        `{"mainbranch": {"name": "main"}}`,
+		repoBase + "/refs/tags/v1.1.0":  annotatedTag,
+		repoBase + "/refs/tags/v1.0.0":  lightweightTag,
+		repoBase + "/refs/tags":         `{"values": [` + annotatedTag + `,` + lightweightTag + `]}`,
+		repoBase + "/commit/" + headSHA: cloudCommitJSON(headSHA, "Latest change", "2025-08-01T08:00:00+00:00"),
+		repoBase + "/commits/main":      `{"values": [` + cloudCommitJSON(headSHA, "Latest change", "2025-08-01T08:00:00+00:00") + `]}`,
+	})
+
+	source, err := NewSource(Cloud, server.URL, "user", "app-password", nil)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("Annotated tag fills the message from the annotation", func(t *testing.T) {
+		got, err := source.ResolveCurrent(ctx, sources.Query{Repo: "team/app", GitRef: "v1.1.0", Kind: sources.KindTag})
+		assert.NoError(t, err)
+		assert.Equal(t, &StandardizedEntity{
+			Ref:         "v1.1.0",
+			URL:         "https://bitbucket.org/team/app/commits/" + tagSHA,
+			Message:     "Release 1.1.0",
+			Author:      "releasemanager",
+			PublishedAt: "2025-07-02 09:00:00 +0000 UTC",
+			TagKind:     sources.TagAnnotated,
+		}, got)
+	})
+
+	t.Run("Lightweight tag falls back to its commit", func(t *testing.T) {
+		got, err := source.ResolveCurrent(ctx, sources.Query{Repo: "team/app", GitRef: "v1.0.0", Kind: sources.KindTag})
+		assert.NoError(t, err)
+		assert.Equal(t, &StandardizedEntity{
+			Ref:         "v1.0.0",
+			URL:         "https://bitbucket.org/team/app/commits/" + tagSHA,
+			Message:     "Tagged change",
+			Author:      "commitauthor",
+			PublishedAt: "2025-07-01 08:00:00 +0000 UTC",
+			TagKind:     sources.TagLightweight,
+		}, got)
+	})
+
+	t.Run("Releases are not supported", func(t *testing.T) {
+		_, err := source.ResolveCurrent(ctx, sources.Query{Repo: "team/app", GitRef: "v1.1.0", Kind: sources.KindRelease})
+		assert.ErrorIs(t, err, sources.ErrNotFound)
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		got, err := source.ResolveCurrent(ctx, sources.Query{Repo: "team/app", GitRef: headSHA, Kind: sources.KindCommit})
+		assert.NoError(t, err)
+		if assert.NotNil(t, got) {
+			assert.Equal(t, headSHA, got.Ref)
+			assert.Equal(t, "Latest change", got.Message)
+		}
+	})
+
+	t.Run("Latest tag", func(t *testing.T) {
+		got, err := source.ResolveLatest(ctx, sources.Query{Repo: "team/app", Kind: sources.KindRelease})
+		assert.NoError(t, err)
+		if assert.NotNil(t, got) {
+			assert.Equal(t, "v1.1.0", got.Ref)
+		}
+	})
+
+	t.Run("Latest tag skips excluded tags", func(t *testing.T) {
+		got, err := source.ResolveLatest(ctx, sources.Query{
+			Repo:   "team/app",
+			Kind:   sources.KindTag,
+			Latest: sources.LatestPolicy{ExcludeTags: []sources.Pattern{sources.MustParsePattern(`^v1\.1\.`)}},
+		})
+		assert.NoError(t, err)
+		if assert.NotNil(t, got) {
+			assert.Equal(t, "v1.0.0", got.Ref)
+		}
+	})
+
+	t.Run("Latest commit on the main branch", func(t *testing.T) {
+		got, err := source.ResolveLatest(ctx, sources.Query{Repo: "team/app", Kind: sources.KindCommit})
+		assert.NoError(t, err)
+		if assert.NotNil(t, got) {
+			assert.Equal(t, headSHA, got.Ref)
+		}
+	})
+}
+
+func TestDataCenterSource(t *testing.T) {
+	const repoBase = "/rest/api/1.0/projects/TEAM/repos/app"
+	tag := `{"id": "refs/tags/v1.1.0", "displayId": "v1.1.0", "type": "TAG", "latestCommit": "` + tagSHA + `"}`
+	tagCommit := `{
+		"id": "` + tagSHA + `",
+		"author": {"name": "commitauthor", "displayName": "Commit Author"},
+		"authorTimestamp": 1751356800000,
+		"message": "Tagged change"
+	}`
+	headCommit := `{"id": "` + headSHA + `", "author": {"name": "headauthor"}, "authorTimestamp": 1754035200000}`
+
+	server := testAPI("Bearer http-access-token").ServeJSON(t, map[string]string{
+		repoBase + "/tags/v1.1.0":       tag,
+		repoBase + "/tags":              `{"values": [` + tag + `]}`,
+		repoBase + "/commits/" + tagSHA: tagCommit,
+		repoBase + "/commits":           `{"values": [` + headCommit + `]}`,
+	})
+
+	source, err := NewSource(DataCenter, server.URL, "", "http-access-token", nil)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("Tag is described by its commit", func(t *testing.T) {
+		got, err := source.ResolveCurrent(ctx, sources.Query{Repo: "TEAM/app", GitRef: "v1.1.0", Kind: sources.KindTag})
+		assert.NoError(t, err)
+		assert.Equal(t, &StandardizedEntity{
+			Ref:         "v1.1.0",
+			URL:         server.URL + "/projects/TEAM/repos/app/commits/" + tagSHA,
+			Message:     "Tagged change",
+			Author:      "commitauthor",
+			PublishedAt: "2025-07-01 08:00:00 +0000 UTC",
+		}, got)
+	})
+
+	t.Run("Missing tag", func(t *testing.T) {
+		_, err := source.ResolveCurrent(ctx, sources.Query{Repo: "TEAM/app", GitRef: "v9.9.9", Kind: sources.KindTag})
+		assert.ErrorIs(t, err, sources.ErrNotFound)
+	})
+
+	t.Run("Latest tag", func(t *testing.T) {
+		got, err := source.ResolveLatest(ctx, sources.Query{Repo: "TEAM/app", Kind: sources.KindTag})
+		assert.NoError(t, err)
+		if assert.NotNil(t, got) {
+			assert.Equal(t, "v1.1.0", got.Ref)
+		}
+	})
+
+	t.Run("Latest commit", func(t *testing.T) {
+		got, err := source.ResolveLatest(ctx, sources.Query{Repo: "TEAM/app", Kind: sources.KindCommit})
+		assert.NoError(t, err)
+		if assert.NotNil(t, got) {
+			assert.Equal(t, headSHA, got.Ref)
+			assert.Equal(t, "2025-08-01 08:00:00 +0000 UTC", got.PublishedAt)
+		}
+	})
+}
+
+func TestNewSourceInvalidFlavor(t *testing.T) {
+	_, err := NewSource("server", "https://bitbucket.example.com", "", "", nil)
+	assert.Error(t, err)
+
+	_, err = NewSource(DataCenter, "", "", "", nil)
+	assert.Error(t, err)
+}
+
+func TestListTagNames(t *testing.T) {
+	const tagCount = 250
+
+	tests := []struct {
+		name    string
+		flavor  Flavor
+		path    string
+		respond func(w http.ResponseWriter, r *http.Request, names []string)
+	}{
+		{
+			name:   "Cloud",
+			flavor: Cloud,
+			path:   "/repositories/team/app/refs/tags",
+			respond: func(w http.ResponseWriter, r *http.Request, names []string) {
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				start, end := (page-1)*pageSize, min(page*pageSize, len(names))
+				values := make([]string, 0, pageSize)
+				for _, name := range names[start:end] {
+					values = append(values, fmt.Sprintf(`{"name": %q}`, name))
+				}
+				next := ""
+				if end < len(names) {
+					next = fmt.Sprintf("http://%s%s?page=%d", r.Host, r.URL.Path, page+1)
+				}
+				_, _ = fmt.Fprintf(w, `{"next": %q, "values": [%s]}`, next, strings.Join(values, ","))
+			},
+		},
+		{
+			name:   "Data Center",
+			flavor: DataCenter,
+			path:   "/rest/api/1.0/projects/PROJ/repos/app/tags",
+			respond: func(w http.ResponseWriter, r *http.Request, names []string) {
+				start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+				end := min(start+pageSize, len(names))
+				values := make([]string, 0, pageSize)
+				for _, name := range names[start:end] {
+					values = append(values, fmt.Sprintf(`{"displayId": %q}`, name))
+				}
+				_, _ = fmt.Fprintf(w, `{"values": [%s], "isLastPage": %t, "nextPageStart": %d}`,
+					strings.Join(values, ","), end == len(names), end)
+			},
+		},
+	}
+
+	names := make([]string, tagCount)
+	for i := range names {
+		names[i] = fmt.Sprintf("v1.%d.0", i)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sourcestest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.path, r.URL.Path)
+				tt.respond(w, r, names)
+			}))
+
+			source, err := NewSource(tt.flavor, server.URL, "", "", nil)
+			assert.NoError(t, err)
+
+			repo := "team/app"
+			if tt.flavor == DataCenter {
+				repo = "PROJ/app"
+			}
+			got, err := source.ListTagNames(context.Background(), repo)
+			assert.NoError(t, err)
+			assert.Equal(t, names, got)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// cloudAPI talks to the Bitbucket Cloud 2.0 REST API
+type cloudAPI struct {
+	client *restClient
+}
+
+type cloudUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+}
+
+type cloudAuthor struct {
+	Raw  string     `json:"raw"`
+	User *cloudUser `json:"user"`
+}
+
+// name returns the nickname of the linked account, falling back to the raw git identity
+func (a *cloudAuthor) name() string {
+	if a == nil {
+		return ""
+	}
+	if a.User != nil && a.User.Nickname != "" {
+		return a.User.Nickname
+	}
+	if a.User != nil && a.User.DisplayName != "" {
+		return a.User.DisplayName
+	}
+	return a.Raw
+}
+
+type cloudCommit struct {
+	Hash    string       `json:"hash"`
+	Date    *time.Time   `json:"date"`
+	Message string       `json:"message"`
+	Author  *cloudAuthor `json:"author"`
+	Links   struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+type cloudTag struct {
+	Name    string       `json:"name"`
+	Message string       `json:"message"`
+	Date    *time.Time   `json:"date"`
+	Tagger  *cloudAuthor `json:"tagger"`
+	Target  *cloudCommit `json:"target"`
+}
+
+func (c *cloudCommit) toCommit() *Commit {
+	if c == nil {
+		return nil
+	}
+	commit := &Commit{
+		Hash:    c.Hash,
+		Message: c.Message,
+		Author:  c.Author.name(),
+		URL:     c.Links.HTML.Href,
+	}
+	if c.Date != nil {
+		commit.Date = c.Date.UTC()
+	}
+	return commit
+}
+
+func (t *cloudTag) toTag() *Tag {
+	tag := &Tag{
+		Name:    t.Name,
+		Message: t.Message,
+		Tagger:  t.Tagger.name(),
+		Commit:  t.Target.toCommit(),
+	}
+	if t.Date != nil {
+		tag.Date = t.Date.UTC()
+	}
+
+	// Only annotated tags have a tagger
+	tag.Kind = sources.TagLightweight
+	if tag.Tagger != "" {
+		tag.Kind = sources.TagAnnotated
+	}
+	return tag
+}
+
+func (a *cloudAPI) repoPath(repo string) string {
+	workspace, repoSlug := splitRepo(repo)
+	return "/repositories/" + workspace + "/" + repoSlug
+}
+
+func (a *cloudAPI) FetchTag(ctx context.Context, repo, name string) (*Tag, error) {
+	var tag cloudTag
+	if err := a.client.get(ctx, a.repoPath(repo)+"/refs/tags/"+url.PathEscape(name), nil, &tag); err != nil {
+		return nil, err
+	}
+	return tag.toTag(), nil
+}
+
+func (a *cloudAPI) FetchLatestTag(ctx context.Context, repo string) (*Tag, error) {
+	query := url.Values{
+		"sort":    {"-target.date"},
+		"pagelen": {"1"},
+	}
+
+	var page struct {
+		Values []*cloudTag `json:"values"`
+	}
+	if err := a.client.get(ctx, a.repoPath(repo)+"/refs/tags", query, &page); err != nil {
+		return nil, err
+	}
+	if len(page.Values) == 0 {
+		return nil, nil
+	}
+	return page.Values[0].toTag(), nil
+}
+
+func (a *cloudAPI) ListTagNames(ctx context.Context, repo string) ([]string, error) {
+	query := url.Values{
+		"sort":    {"-target.date"},
+		"pagelen": {strconv.Itoa(pageSize)},
+		"fields":  {"next,values.name"},
+	}
+
+	var names []string
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var response struct {
+			Next   string `json:"next"`
+			Values []struct {
+				Name string `json:"name"`
+			} `json:"values"`
+		}
+		if err := a.client.get(ctx, a.repoPath(repo)+"/refs/tags", query, &response); err != nil {
+			return nil, err
+		}
+		for _, tag := range response.Values {
+			names = append(names, tag.Name)
+		}
+		if response.Next == "" {
+			return names, nil
+		}
+	}
+}
+
+func (a *cloudAPI) FetchCommit(ctx context.Context, repo, sha string) (*Commit, error) {
+	var commit cloudCommit
+	if err := a.client.get(ctx, a.repoPath(repo)+"/commit/"+url.PathEscape(sha), nil, &commit); err != nil {
+		return nil, err
+	}
+	return commit.toCommit(), nil
+}
+
+func (a *cloudAPI) FetchLatestCommit(ctx context.Context, repo, branch string) (*Commit, error) {
+	if branch == "" {
+		// Listing commits without a revision spans all branches, so look up the main branch first
+		var repository struct {
+			MainBranch *struct {
+				Name string `json:"name"`
+			} `json:"mainbranch"`
+		}
+		if err := a.client.get(ctx, a.repoPath(repo), nil, &repository); err != nil {
+			return nil, err
+		}
+		if repository.MainBranch == nil || repository.MainBranch.Name == "" {
+			return nil, fmt.Errorf("no main branch found for repo %s", repo)
+		}
+		branch = repository.MainBranch.Name
+	}
+
+	var page struct {
+		Values []*cloudCommit `json:"values"`
+	}
+	path := a.repoPath(repo) + "/commits/" + url.PathEscape(branch)
+	if err := a.client.get(ctx, path, url.Values{"pagelen": {"1"}}, &page); err != nil {
+		return nil, err
+	}
+	if len(page.Values) == 0 {
+		return nil, fmt.Errorf("no commits found")
+	}
+	return page.Values[0].toCommit(), nil
+}
@@ -0,0 +1,146 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dataCenterAPI talks to the Bitbucket Data Center 1.0 REST API.
+// That API does not expose tag annotations, so tags are described by the commit they point to.
+type dataCenterAPI struct {
+	client *restClient
+	webURL string // Base URL of the instance, used to build commit links
+}
+
+type dataCenterCommit struct {
+	ID     string `json:"id"`
+	Author *struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"author"`
+	AuthorTimestamp int64  `json:"authorTimestamp"` // Milliseconds since the epoch
+	Message         string `json:"message"`
+}
+
+type dataCenterTag struct {
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+func (a *dataCenterAPI) repoPath(repo string) string {
+	project, repoSlug := splitRepo(repo)
+	return "/projects/" + project + "/repos/" + repoSlug
+}
+
+func (a *dataCenterAPI) toCommit(repo string, c *dataCenterCommit) *Commit {
+	commit := &Commit{
+		Hash:    c.ID,
+		Message: c.Message,
+		URL:     a.webURL + a.repoPath(repo) + "/commits/" + c.ID,
+	}
+	if c.Author != nil {
+		commit.Author = c.Author.Name
+	}
+	if c.AuthorTimestamp != 0 {
+		commit.Date = time.UnixMilli(c.AuthorTimestamp).UTC()
+	}
+	return commit
+}
+
+// toTag looks up the commit a tag points to, since the tag itself carries no details
+func (a *dataCenterAPI) toTag(ctx context.Context, repo string, t *dataCenterTag) (*Tag, error) {
+	commit, err := a.FetchCommit(ctx, repo, t.LatestCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch commit for tag %s: %v", t.DisplayID, err)
+	}
+	return &Tag{Name: t.DisplayID, Commit: commit}, nil
+}
+
+func (a *dataCenterAPI) FetchTag(ctx context.Context, repo, name string) (*Tag, error) {
+	var tag dataCenterTag
+	if err := a.client.get(ctx, a.repoPath(repo)+"/tags/"+url.PathEscape(name), nil, &tag); err != nil {
+		return nil, err
+	}
+	return a.toTag(ctx, repo, &tag)
+}
+
+func (a *dataCenterAPI) FetchLatestTag(ctx context.Context, repo string) (*Tag, error) {
+	query := url.Values{
+		"orderBy": {"MODIFICATION"},
+		"limit":   {"1"},
+	}
+
+	var page struct {
+		Values []*dataCenterTag `json:"values"`
+	}
+	if err := a.client.get(ctx, a.repoPath(repo)+"/tags", query, &page); err != nil {
+		return nil, err
+	}
+	if len(page.Values) == 0 {
+		return nil, nil
+	}
+	return a.toTag(ctx, repo, page.Values[0])
+}
+
+func (a *dataCenterAPI) ListTagNames(ctx context.Context, repo string) ([]string, error) {
+	query := url.Values{
+		"orderBy": {"MODIFICATION"},
+		"limit":   {strconv.Itoa(pageSize)},
+	}
+
+	var names []string
+	for start := 0; ; {
+		query.Set("start", strconv.Itoa(start))
+		var page struct {
+			Values        []*dataCenterTag `json:"values"`
+			IsLastPage    bool             `json:"isLastPage"`
+			NextPageStart int              `json:"nextPageStart"`
+		}
+		if err := a.client.get(ctx, a.repoPath(repo)+"/tags", query, &page); err != nil {
+			return nil, err
+		}
+		for _, tag := range page.Values {
+			names = append(names, tag.DisplayID)
+		}
+		if page.IsLastPage || len(page.Values) == 0 {
+			return names, nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+func (a *dataCenterAPI) FetchCommit(ctx context.Context, repo, sha string) (*Commit, error) {
+	var commit dataCenterCommit
+	if err := a.client.get(ctx, a.repoPath(repo)+"/commits/"+url.PathEscape(sha), nil, &commit); err != nil {
+		return nil, err
+	}
+	return a.toCommit(repo, &commit), nil
+}
+
+func (a *dataCenterAPI) FetchLatestCommit(ctx context.Context, repo, branch string) (*Commit, error) {
+	// Commits are listed from the default branch unless another one is requested
+	query := url.Values{"limit": {"1"}}
+	if branch != "" {
+		query.Set("until", "refs/heads/"+branch)
+	}
+
+	var page struct {
+		Values []*dataCenterCommit `json:"values"`
+	}
+	if err := a.client.get(ctx, a.repoPath(repo)+"/commits", query, &page); err != nil {
+		return nil, err
+	}
+	if len(page.Values) == 0 {
+		return nil, fmt.Errorf("no commits found")
+	}
+	return a.toCommit(repo, page.Values[0]), nil
+}
+
+// dataCenterAPIURL returns the REST API base URL of the instance at webURL
+func dataCenterAPIURL(webURL string) string {
+	return strings.TrimSuffix(webURL, "/") + "/rest/api/1.0"
+}
@@ -0,0 +1,94 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Source resolves references against Bitbucket Cloud or Bitbucket Data Center.
+// Bitbucket has no releases, so only tags and commits are resolved.
+type Source struct {
+	api api
+}
+
+// NewSource creates a Source for the given Bitbucket flavor.
+// For Cloud, baseURL is the API base URL and defaults to DefaultCloudURL; for Data Center it is the instance URL.
+// username is only needed with Cloud app passwords, otherwise token is sent as a bearer token.
+func NewSource(flavor Flavor, baseURL, username, token string, httpClient *http.Client) (*Source, error) {
+	switch flavor {
+	case Cloud:
+		if baseURL == "" {
+			baseURL = DefaultCloudURL
+		}
+		client := &restClient{baseURL: baseURL, username: username, token: token, httpClient: httpClient}
+		return &Source{api: &cloudAPI{client: client}}, nil
+	case DataCenter:
+		if baseURL == "" {
+			return nil, fmt.Errorf("bitbucket data center requires a base URL")
+		}
+		client := &restClient{baseURL: dataCenterAPIURL(baseURL), username: username, token: token, httpClient: httpClient}
+		return &Source{api: &dataCenterAPI{client: client, webURL: baseURL}}, nil
+	}
+	return nil, fmt.Errorf("unknown bitbucket flavor %q", flavor)
+}
+
+// ResolveCurrent resolves the gitRef as a Bitbucket tag or commit
+func (s *Source) ResolveCurrent(ctx context.Context, q sources.Query) (*StandardizedEntity, error) {
+	switch q.Kind {
+	case sources.KindTag:
+		tag, err := s.api.FetchTag(ctx, q.Repo, q.GitRef)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeTag(tag), nil
+	case sources.KindCommit:
+		if !sources.IsCommitSHA(q.GitRef) {
+			return nil, fmt.Errorf("%w format: Expected a commit SHA", sources.ErrInvalidRef)
+		}
+		commit, err := s.api.FetchCommit(ctx, q.Repo, q.GitRef)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeCommit(commit), nil
+	}
+	return nil, fmt.Errorf("%w: Bitbucket repositories have no %ss", sources.ErrNotFound, q.Kind)
+}
+
+// ResolveLatest returns the head of the tracked branch for commits and the newest tag admitted by q.Latest otherwise
+func (s *Source) ResolveLatest(ctx context.Context, q sources.Query) (*StandardizedEntity, error) {
+	if q.Kind == sources.KindCommit {
+		commit, err := s.api.FetchLatestCommit(ctx, q.Repo, q.Branch)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeCommit(commit), nil
+	}
+
+	tag, err := s.api.FetchLatestTag(ctx, q.Repo)
+	if err != nil || tag == nil {
+		return nil, err
+	}
+	if !q.Latest.AdmitsTag(tag.Name) {
+		// Search the older tags only when the newest one is excluded
+		names, err := s.api.ListTagNames(ctx, q.Repo)
+		if err != nil {
+			return nil, err
+		}
+		name := q.Latest.FirstAdmittedTag(names)
+		if name == "" {
+			return nil, nil
+		}
+		if tag, err = s.api.FetchTag(ctx, q.Repo, name); err != nil {
+			return nil, err
+		}
+	}
+	return StandardizeTag(tag), nil
+}
+
+// ListTagNames returns the names of every tag of repo, most recently created first
+func (s *Source) ListTagNames(ctx context.Context, repo string) ([]string, error) {
+	return s.api.ListTagNames(ctx, repo)
+}
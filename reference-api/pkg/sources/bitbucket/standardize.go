@@ -0,0 +1,55 @@
+package bitbucket
+
+import (
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// StandardizedEntity is shared by every source
+type StandardizedEntity = sources.StandardizedEntity
+
+// StandardizeCommit converts a Commit into a StandardizedEntity
+func StandardizeCommit(commit *Commit) *StandardizedEntity {
+	if commit == nil {
+		return nil
+	}
+
+	publishedAt := ""
+	if !commit.Date.IsZero() {
+		publishedAt = commit.Date.String()
+	}
+
+	return &StandardizedEntity{
+		Ref:         commit.Hash,
+		URL:         commit.URL,
+		Message:     commit.Message,
+		Author:      commit.Author,
+		PublishedAt: publishedAt,
+	}
+}
+
+// StandardizeTag converts a Tag into a StandardizedEntity.
+// Bitbucket has no releases, so the annotation of annotated tags fills the message,
+// while lightweight tags fall back to the commit they point to.
+func StandardizeTag(tag *Tag) *StandardizedEntity {
+	if tag == nil {
+		return nil
+	}
+
+	entity := StandardizeCommit(tag.Commit)
+	if entity == nil {
+		entity = &StandardizedEntity{}
+	}
+	entity.Ref = tag.Name
+	entity.TagKind = tag.Kind
+
+	if tag.Message != "" {
+		entity.Message = tag.Message
+	}
+	if tag.Tagger != "" {
+		entity.Author = tag.Tagger
+	}
+	if !tag.Date.IsZero() {
+		entity.PublishedAt = tag.Date.String()
+	}
+	return entity
+}
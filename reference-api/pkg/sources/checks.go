@@ -0,0 +1,74 @@
+package sources
+
+import "context"
+
+// States of CI checks and of their summary
+const (
+	CheckSuccess = "success"
+	CheckFailure = "failure"
+	CheckPending = "pending"
+)
+
+// Check is a single CI check or commit status reported for a reference
+type Check struct {
+	Name  string `json:"name"`  // Name or context of the check
+	State string `json:"state"` // CheckSuccess, CheckFailure or CheckPending
+	URL   string `json:"url"`   // Web page of the check
+}
+
+// Checks summarizes the CI checks and commit statuses reported for a reference
+type Checks struct {
+	State   string   `json:"state"`   // CheckFailure if any check failed, else CheckPending if any is running, else CheckSuccess
+	Success int      `json:"success"` // Number of successful checks
+	Failure int      `json:"failure"` // Number of failed checks
+	Pending int      `json:"pending"` // Number of checks still running
+	Checks  []*Check `json:"checks"`
+}
+
+// SummarizeChecks counts the checks by state, returning nil when there are none
+func SummarizeChecks(checks []*Check) *Checks {
+	if len(checks) == 0 {
+		return nil
+	}
+
+	summary := &Checks{Checks: checks}
+	for _, check := range checks {
+		switch check.State {
+		case CheckSuccess:
+			summary.Success++
+		case CheckFailure:
+			summary.Failure++
+		default:
+			summary.Pending++
+		}
+	}
+
+	switch {
+	case summary.Failure > 0:
+		summary.State = CheckFailure
+	case summary.Pending > 0:
+		summary.State = CheckPending
+	default:
+		summary.State = CheckSuccess
+	}
+	return summary
+}
+
+// CheckLister is implemented by sources that can list the CI checks reported for a reference
+type CheckLister interface {
+	// ChecksFor returns the CI checks and commit statuses reported for the commit ref points to
+	ChecksFor(ctx context.Context, repo, ref string) ([]*Check, error)
+}
+
+// checksFor summarizes the checks reported for the entity when the source can list them
+func checksFor(ctx context.Context, src Source, repo string, entity *StandardizedEntity) (*Checks, error) {
+	lister, ok := src.(CheckLister)
+	if !ok || entity == nil {
+		return nil, nil
+	}
+	checks, err := lister.ChecksFor(ctx, repo, entity.Ref)
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeChecks(checks), nil
+}
@@ -0,0 +1,42 @@
+package sources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkedSource is a taggedSource whose references all have the given checks
+type checkedSource struct {
+	taggedSource
+	checks []*Check
+}
+
+func (s *checkedSource) ChecksFor(ctx context.Context, repo, ref string) ([]*Check, error) {
+	return s.checks, nil
+}
+
+func TestSummarizeChecks(t *testing.T) {
+	success := &Check{Name: "build", State: CheckSuccess}
+	failure := &Check{Name: "test", State: CheckFailure}
+	pending := &Check{Name: "deploy", State: CheckPending}
+
+	assert.Nil(t, SummarizeChecks(nil))
+	assert.Equal(t, &Checks{State: CheckSuccess, Success: 1, Checks: []*Check{success}}, SummarizeChecks([]*Check{success}))
+	assert.Equal(t, CheckPending, SummarizeChecks([]*Check{success, pending}).State)
+
+	summary := SummarizeChecks([]*Check{success, pending, failure})
+	assert.Equal(t, CheckFailure, summary.State)
+	assert.Equal(t, []int{1, 1, 1}, []int{summary.Success, summary.Failure, summary.Pending})
+}
+
+func TestResolveChecks(t *testing.T) {
+	checks := []*Check{{Name: "build", State: CheckSuccess}}
+	source := &checkedSource{taggedSource: taggedSource{tags: []string{"v2.0.0", "v1.0.0"}}, checks: checks}
+
+	output, err := Resolve(context.Background(), source, Query{GitRef: "v1.0.0", Kind: KindTag})
+	assert.NoError(t, err)
+	assert.Equal(t, SummarizeChecks(checks), output.Current.Checks)
+	assert.Equal(t, SummarizeChecks(checks), output.Latest.Checks)
+}
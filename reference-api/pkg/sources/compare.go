@@ -0,0 +1,43 @@
+package sources
+
+import "context"
+
+// Comparison describes how far the current reference is from the latest one
+type Comparison struct {
+	Status   string `json:"status"`    // "identical", "behind", "ahead" or "diverged", from the current reference's side
+	BehindBy int    `json:"behind_by"` // Commits in the latest reference that are missing from the current one
+	AheadBy  int    `json:"ahead_by"`  // Commits in the current reference that are missing from the latest one
+	URL      string `json:"url"`       // Web page comparing the two references, when the source has one
+}
+
+// Comparer is implemented by sources that can count the commits between two references of a repository
+type Comparer interface {
+	// Compare compares the current reference with the latest one
+	Compare(ctx context.Context, repo, current, latest string) (*Comparison, error)
+}
+
+// ComparisonStatus returns the status of a comparison from the commits on either side
+func ComparisonStatus(behindBy, aheadBy int) string {
+	switch {
+	case behindBy == 0 && aheadBy == 0:
+		return "identical"
+	case aheadBy == 0:
+		return "behind"
+	case behindBy == 0:
+		return "ahead"
+	}
+	return "diverged"
+}
+
+// compare compares the current and latest references when the source supports it.
+// Identical references are reported without asking the source.
+func compare(ctx context.Context, src Source, repo string, current, latest *StandardizedEntity) (*Comparison, error) {
+	comparer, ok := src.(Comparer)
+	if !ok || current == nil || latest == nil {
+		return nil, nil
+	}
+	if current.Ref == latest.Ref {
+		return &Comparison{Status: ComparisonStatus(0, 0)}, nil
+	}
+	return comparer.Compare(ctx, repo, current.Ref, latest.Ref)
+}
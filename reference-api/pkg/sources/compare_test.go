@@ -0,0 +1,78 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// comparingSource is a taggedSource that compares references with compare
+type comparingSource struct {
+	taggedSource
+	compare func(current, latest string) (*Comparison, error)
+}
+
+func (s *comparingSource) Compare(ctx context.Context, repo, current, latest string) (*Comparison, error) {
+	return s.compare(current, latest)
+}
+
+func TestComparisonStatus(t *testing.T) {
+	assert.Equal(t, "identical", ComparisonStatus(0, 0))
+	assert.Equal(t, "behind", ComparisonStatus(12, 0))
+	assert.Equal(t, "ahead", ComparisonStatus(0, 3))
+	assert.Equal(t, "diverged", ComparisonStatus(12, 3))
+}
+
+func TestResolveComparison(t *testing.T) {
+	tags := taggedSource{tags: []string{"v2.0.0", "v1.0.0"}}
+
+	tests := []struct {
+		name   string
+		source Source
+		gitRef string
+		want   *Comparison
+	}{
+		{
+			name:   "Source without comparisons",
+			source: &tags,
+			gitRef: "v1.0.0",
+		},
+		{
+			name: "Behind latest",
+			source: &comparingSource{taggedSource: tags, compare: func(current, latest string) (*Comparison, error) {
+				assert.Equal(t, "v1.0.0", current)
+				assert.Equal(t, "v2.0.0", latest)
+				return &Comparison{Status: "behind", BehindBy: 12}, nil
+			}},
+			gitRef: "v1.0.0",
+			want:   &Comparison{Status: "behind", BehindBy: 12},
+		},
+		{
+			name: "Latest deployed",
+			source: &comparingSource{taggedSource: tags, compare: func(string, string) (*Comparison, error) {
+				t.Error("identical references should not be compared")
+				return nil, nil
+			}},
+			gitRef: "v2.0.0",
+			want:   &Comparison{Status: "identical"},
+		},
+		{
+			name: "Failed comparison",
+			source: &comparingSource{taggedSource: tags, compare: func(string, string) (*Comparison, error) {
+				return nil, errors.New("compare failed")
+			}},
+			gitRef: "v1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(context.Background(), tt.source, Query{GitRef: tt.gitRef, Kind: KindTag})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Comparison)
+			assert.NotNil(t, got.Current)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+const (
+	// DefaultFetchInterval is how long a mirror is used before it is fetched again
+	DefaultFetchInterval = time.Minute
+	// DefaultMaxMirrors is how many repositories are mirrored at most
+	DefaultMaxMirrors = 50
+)
+
+// repoPathRegex matches repository paths of slash separated names, none of them starting with a dot
+var repoPathRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*(/[A-Za-z0-9_][A-Za-z0-9._-]*)*$`)
+
+// Config describes the remote repositories to mirror and where to
+type Config struct {
+	RemoteURL     string        // Remote URL, containing a "{repo}" placeholder or to which the repository path is appended
+	MirrorDir     string        // Directory the bare mirrors are cloned into
+	FetchInterval time.Duration // How long a mirror is used before it is fetched again, DefaultFetchInterval when 0
+
+	// Repositories are the path.Match patterns of the repositories that may be mirrored, such as "team/*".
+	// Any repository may be mirrored when empty.
+	Repositories []string
+	MaxMirrors   int // How many repositories are mirrored at most, DefaultMaxMirrors when 0
+}
+
+// mirror is a bare local copy of a single remote repository
+type mirror struct {
+	mu        sync.Mutex
+	dir       string
+	remote    string
+	lastFetch time.Time
+}
+
+// Mirrors keeps bare mirrors of remote repositories up to date in a local directory
+type Mirrors struct {
+	remoteURL     string
+	dir           string
+	fetchInterval time.Duration
+	repositories  []string
+	maxMirrors    int
+
+	mu      sync.Mutex
+	mirrors map[string]*mirror
+}
+
+// NewMirrors creates mirrors of the repositories described by config
+func NewMirrors(config Config) *Mirrors {
+	if config.FetchInterval <= 0 {
+		config.FetchInterval = DefaultFetchInterval
+	}
+	if config.MaxMirrors <= 0 {
+		config.MaxMirrors = DefaultMaxMirrors
+	}
+	return &Mirrors{
+		remoteURL:     config.RemoteURL,
+		dir:           config.MirrorDir,
+		fetchInterval: config.FetchInterval,
+		repositories:  config.Repositories,
+		maxMirrors:    config.MaxMirrors,
+		mirrors:       make(map[string]*mirror),
+	}
+}
+
+// checkRepository returns an error when repo is not a valid repository path, or not one that may be mirrored
+func (m *Mirrors) checkRepository(repo string) error {
+	if !repoPathRegex.MatchString(repo) {
+		return fmt.Errorf("%w: invalid repository path %q", sources.ErrInvalidRef, repo)
+	}
+	if len(m.repositories) == 0 {
+		return nil
+	}
+	for _, pattern := range m.repositories {
+		if matched, _ := path.Match(pattern, repo); matched {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: repository %s is not mirrored", sources.ErrNotFound, repo)
+}
+
+// remoteFor returns the remote URL of repo
+func (m *Mirrors) remoteFor(repo string) string {
+	if strings.Contains(m.remoteURL, "{repo}") {
+		return strings.ReplaceAll(m.remoteURL, "{repo}", repo)
+	}
+	return strings.TrimSuffix(m.remoteURL, "/") + "/" + repo
+}
+
+// Sync returns the git directory of an up to date mirror of repo, cloning or fetching it as needed
+func (m *Mirrors) Sync(ctx context.Context, repo string) (string, error) {
+	if err := m.checkRepository(repo); err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	mr, ok := m.mirrors[repo]
+	if !ok {
+		if len(m.mirrors) >= m.maxMirrors {
+			m.mu.Unlock()
+			return "", fmt.Errorf("cannot mirror %s: the limit of %d mirrors is reached", repo, m.maxMirrors)
+		}
+		mr = &mirror{
+			dir:    filepath.Join(m.dir, filepath.FromSlash(repo)),
+			remote: m.remoteFor(repo),
+		}
+		m.mirrors[repo] = mr
+	}
+	m.mu.Unlock()
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if time.Since(mr.lastFetch) < m.fetchInterval {
+		return mr.dir, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(mr.dir, "HEAD")); err != nil {
+		log.Printf("Cloning mirror of %s into %s", mr.remote, mr.dir)
+		if err := os.MkdirAll(filepath.Dir(mr.dir), 0o755); err != nil {
+			return "", fmt.Errorf("failed to create mirror directory: %v", err)
+		}
+		if _, err := run(ctx, "", "clone", "--mirror", "--quiet", mr.remote, mr.dir); err != nil {
+			m.evict(repo, mr)
+			return "", err
+		}
+	} else if _, err := run(ctx, mr.dir, "fetch", "--prune", "--quiet", "origin"); err != nil {
+		return "", err
+	}
+
+	mr.lastFetch = time.Now()
+	return mr.dir, nil
+}
+
+// evict forgets the mirror of repo after its clone failed and removes what was cloned, so that it doesn't count
+// against the limit of mirrors
+func (m *Mirrors) evict(repo string, mr *mirror) {
+	m.mu.Lock()
+	if m.mirrors[repo] == mr {
+		delete(m.mirrors, repo)
+	}
+	m.mu.Unlock()
+
+	if err := os.RemoveAll(mr.dir); err != nil {
+		log.Printf("Failed to remove the failed mirror of %s: %v", repo, err)
+	}
+}
+
+// run executes a git command, against gitDir when it is set, and returns its standard output
+func run(ctx context.Context, gitDir string, args ...string) (string, error) {
+	if gitDir != "" {
+		args = append([]string{"--git-dir", gitDir}, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
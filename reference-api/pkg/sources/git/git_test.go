@@ -0,0 +1,362 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitAt runs a git command in dir with a fixed identity and date, returning its trimmed output
+func gitAt(t *testing.T, dir, date string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Test Author", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_DATE="+date,
+		"GIT_CONFIG_GLOBAL=/dev/null",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	return strings.TrimSpace(string(out))
+}
+
+// newTestRemote creates a repository "team/app" under a temporary directory served as a file:// remote.
+// It has a lightweight tag v1.0.0 on the first commit and an annotated tag v1.1.0 on the second one.
+func newTestRemote(t *testing.T) (remoteURL string, repoDir string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	repoDir = filepath.Join(root, "team", "app")
+	require.NoError(t, os.MkdirAll(repoDir, 0o755))
+
+	gitAt(t, repoDir, "2025-01-01T12:00:00Z", "init", "--quiet", "--initial-branch=main")
+	gitAt(t, repoDir, "2025-01-01T12:00:00Z", "commit", "--quiet", "--allow-empty", "-m", "Initial commit")
+	gitAt(t, repoDir, "2025-01-01T12:00:00Z", "tag", "v1.0.0")
+	gitAt(t, repoDir, "2025-02-01T12:00:00Z", "commit", "--quiet", "--allow-empty", "-m", "Add feature\n\nWith details")
+	gitAt(t, repoDir, "2025-02-02T09:30:00Z", "tag", "-a", "v1.1.0", "-m", "Release 1.1.0\n\nChangelog")
+
+	return "file://" + root, repoDir
+}
+
+func TestSourceResolveCurrent(t *testing.T) {
+	remoteURL, repoDir := newTestRemote(t)
+	source := NewSource(Config{RemoteURL: remoteURL, MirrorDir: t.TempDir(), FetchInterval: time.Minute})
+
+	firstSHA := gitAt(t, repoDir, "", "rev-parse", "v1.0.0^{commit}")
+	secondSHA := gitAt(t, repoDir, "", "rev-parse", "HEAD")
+
+	tests := []struct {
+		name    string
+		kind    sources.Kind
+		gitRef  string
+		want    *StandardizedEntity
+		wantErr error
+	}{
+		{
+			name:   "Lightweight tag uses its commit",
+			kind:   sources.KindTag,
+			gitRef: "v1.0.0",
+			want: &StandardizedEntity{
+				Ref:         "v1.0.0",
+				Message:     "Initial commit",
+				Author:      "Test Author",
+				PublishedAt: "2025-01-01 12:00:00 +0000 UTC",
+				TagKind:     sources.TagLightweight,
+			},
+		},
+		{
+			name:   "Annotated tag uses its own message and date",
+			kind:   sources.KindTag,
+			gitRef: "v1.1.0",
+			want: &StandardizedEntity{
+				Ref:         "v1.1.0",
+				Message:     "Release 1.1.0\n\nChangelog",
+				Author:      "Test Author",
+				PublishedAt: "2025-02-02 09:30:00 +0000 UTC",
+				TagKind:     sources.TagAnnotated,
+			},
+		},
+		{
+			name:   "Full commit SHA",
+			kind:   sources.KindCommit,
+			gitRef: secondSHA,
+			want: &StandardizedEntity{
+				Ref:         secondSHA,
+				Message:     "Add feature\n\nWith details",
+				Author:      "Test Author",
+				PublishedAt: "2025-02-01 12:00:00 +0000 UTC",
+			},
+		},
+		{
+			name:   "Short commit SHA",
+			kind:   sources.KindCommit,
+			gitRef: firstSHA[:10],
+			want: &StandardizedEntity{
+				Ref:         firstSHA,
+				Message:     "Initial commit",
+				Author:      "Test Author",
+				PublishedAt: "2025-01-01 12:00:00 +0000 UTC",
+			},
+		},
+		{
+			name:    "Missing tag",
+			kind:    sources.KindTag,
+			gitRef:  "v9.9.9",
+			wantErr: sources.ErrNotFound,
+		},
+		{
+			name:    "Missing commit",
+			kind:    sources.KindCommit,
+			gitRef:  "0000000000000000000000000000000000000000",
+			wantErr: sources.ErrNotFound,
+		},
+		{
+			name:    "Releases are not supported",
+			kind:    sources.KindRelease,
+			gitRef:  "v1.0.0",
+			wantErr: sources.ErrNotFound,
+		},
+		{
+			name:    "Invalid commit SHA",
+			kind:    sources.KindCommit,
+			gitRef:  "v1.0.0",
+			wantErr: sources.ErrInvalidRef,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := source.ResolveCurrent(context.Background(), sources.Query{
+				Repo:   "team/app",
+				GitRef: tt.gitRef,
+				Kind:   tt.kind,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSourceResolveLatest(t *testing.T) {
+	remoteURL, repoDir := newTestRemote(t)
+	source := NewSource(Config{RemoteURL: remoteURL, MirrorDir: t.TempDir(), FetchInterval: time.Minute})
+	ctx := context.Background()
+
+	latestTag, err := source.ResolveLatest(ctx, sources.Query{Repo: "team/app", Kind: sources.KindTag})
+	assert.NoError(t, err)
+	if assert.NotNil(t, latestTag) {
+		assert.Equal(t, "v1.1.0", latestTag.Ref)
+	}
+
+	latestCommit, err := source.ResolveLatest(ctx, sources.Query{Repo: "team/app", Kind: sources.KindCommit})
+	assert.NoError(t, err)
+	if assert.NotNil(t, latestCommit) {
+		assert.Equal(t, gitAt(t, repoDir, "", "rev-parse", "HEAD"), latestCommit.Ref)
+	}
+}
+
+func TestMirrorsFetchNewReferences(t *testing.T) {
+	remoteURL, repoDir := newTestRemote(t)
+
+	// A negative interval falls back to the default, so use a tiny one to fetch on every sync
+	source := NewSource(Config{RemoteURL: remoteURL, MirrorDir: t.TempDir(), FetchInterval: time.Nanosecond})
+	ctx := context.Background()
+
+	_, err := source.ResolveCurrent(ctx, sources.Query{Repo: "team/app", GitRef: "v2.0.0", Kind: sources.KindTag})
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+
+	gitAt(t, repoDir, "2025-03-01T12:00:00Z", "tag", "v2.0.0")
+
+	got, err := source.ResolveCurrent(ctx, sources.Query{Repo: "team/app", GitRef: "v2.0.0", Kind: sources.KindTag})
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, "v2.0.0", got.Ref)
+	}
+}
+
+func TestMirrorsRejectInvalidRepository(t *testing.T) {
+	mirrors := NewMirrors(Config{
+		RemoteURL: "file:///nonexistent", MirrorDir: t.TempDir(), Repositories: []string{"team/*", "*"},
+	})
+
+	for _, repo := range []string{"", "../outside", "team/../../outside", "/etc", "team//app", "team/.git", "team/app/"} {
+		t.Run(repo, func(t *testing.T) {
+			_, err := mirrors.Sync(context.Background(), repo)
+			assert.ErrorIs(t, err, sources.ErrInvalidRef)
+		})
+	}
+}
+
+func TestMirrorsLimitRepositories(t *testing.T) {
+	remoteURL, _ := newTestRemote(t)
+	dir := t.TempDir()
+	mirrors := NewMirrors(Config{
+		RemoteURL: remoteURL, MirrorDir: dir, Repositories: []string{"team/*"}, MaxMirrors: 1,
+	})
+	ctx := context.Background()
+
+	_, err := mirrors.Sync(ctx, "other/app")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+
+	// Failed clones are evicted, so they don't count against the limit nor leave files behind
+	_, err = mirrors.Sync(ctx, "team/missing")
+	assert.Error(t, err)
+	assert.NoDirExists(t, filepath.Join(dir, "team", "missing"))
+
+	_, err = mirrors.Sync(ctx, "team/app")
+	assert.NoError(t, err)
+
+	_, err = mirrors.Sync(ctx, "team/other")
+	assert.ErrorContains(t, err, "the limit of 1 mirrors is reached")
+}
+
+func TestSourceResolveLatestByVersion(t *testing.T) {
+	remoteURL, repoDir := newTestRemote(t)
+	// A patch for the previous minor version, tagged after v1.1.0
+	gitAt(t, repoDir, "2025-03-01T12:00:00Z", "tag", "-a", "v1.0.1", "-m", "Backport", "v1.0.0")
+
+	source := NewSource(Config{RemoteURL: remoteURL, MirrorDir: t.TempDir(), FetchInterval: time.Minute})
+	ctx := context.Background()
+
+	names, err := source.ListTagNames(ctx, "team/app")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.1", "v1.1.0", "v1.0.0"}, names)
+
+	byDate, err := sources.ResolveLatest(ctx, source, sources.Query{Repo: "team/app", Kind: sources.KindTag})
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.1", byDate.Ref)
+
+	byVersion, err := sources.ResolveLatest(ctx, source, sources.Query{
+		Repo:   "team/app",
+		Kind:   sources.KindTag,
+		Latest: sources.LatestPolicy{Strategy: sources.LatestByVersion},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.1.0", byVersion.Ref)
+}
+
+func TestSourceResolveLatestSkipsExcludedTags(t *testing.T) {
+	remoteURL, repoDir := newTestRemote(t)
+	gitAt(t, repoDir, "2025-03-01T12:00:00Z", "tag", "-a", "v2.0.0-rc1", "-m", "Release candidate")
+
+	source := NewSource(Config{RemoteURL: remoteURL, MirrorDir: t.TempDir(), FetchInterval: time.Minute})
+	ctx := context.Background()
+
+	latest, err := source.ResolveLatest(ctx, sources.Query{Repo: "team/app", Kind: sources.KindTag})
+	assert.NoError(t, err)
+	if assert.NotNil(t, latest) {
+		assert.Equal(t, "v2.0.0-rc1", latest.Ref)
+	}
+
+	latest, err = source.ResolveLatest(ctx, sources.Query{
+		Repo:   "team/app",
+		Kind:   sources.KindTag,
+		Latest: sources.LatestPolicy{ExcludeTags: []sources.Pattern{sources.MustParsePattern(`-rc\d*$`)}},
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, latest) {
+		assert.Equal(t, "v1.1.0", latest.Ref)
+	}
+}
+
+func TestSourceResolveLatestOnBranch(t *testing.T) {
+	remoteURL, repoDir := newTestRemote(t)
+	gitAt(t, repoDir, "2025-03-01T12:00:00Z", "checkout", "--quiet", "-b", "release")
+	gitAt(t, repoDir, "2025-03-01T12:00:00Z", "commit", "--quiet", "--allow-empty", "-m", "Release fix")
+	releaseHead := gitAt(t, repoDir, "", "rev-parse", "HEAD")
+	gitAt(t, repoDir, "", "checkout", "--quiet", "main")
+
+	source := NewSource(Config{RemoteURL: remoteURL, MirrorDir: t.TempDir(), FetchInterval: time.Minute})
+	ctx := context.Background()
+
+	latest, err := source.ResolveLatest(ctx, sources.Query{Repo: "team/app", Kind: sources.KindCommit, Branch: "release"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, latest) {
+		assert.Equal(t, releaseHead, latest.Ref)
+		assert.Equal(t, "Release fix", latest.Message)
+	}
+
+	_, err = source.ResolveLatest(ctx, sources.Query{Repo: "team/app", Kind: sources.KindCommit, Branch: "missing"})
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestSourceCompare(t *testing.T) {
+	remoteURL, repoDir := newTestRemote(t)
+	gitAt(t, repoDir, "2025-03-01T12:00:00Z", "checkout", "--quiet", "-b", "hotfix", "v1.0.0")
+	gitAt(t, repoDir, "2025-03-01T12:00:00Z", "commit", "--quiet", "--allow-empty", "-m", "Hotfix")
+	gitAt(t, repoDir, "2025-03-01T12:00:00Z", "tag", "v1.0.1")
+	gitAt(t, repoDir, "", "checkout", "--quiet", "main")
+
+	source := NewSource(Config{RemoteURL: remoteURL, MirrorDir: t.TempDir(), FetchInterval: time.Minute})
+	ctx := context.Background()
+
+	comparison, err := source.Compare(ctx, "team/app", "v1.0.0", "v1.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, &sources.Comparison{Status: "behind", BehindBy: 1}, comparison)
+
+	comparison, err = source.Compare(ctx, "team/app", "v1.0.1", "v1.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, &sources.Comparison{Status: "diverged", BehindBy: 1, AheadBy: 1}, comparison)
+
+	_, err = source.Compare(ctx, "team/app", "v9.9.9", "v1.1.0")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestSourceListCommitsBetween(t *testing.T) {
+	remoteURL, repoDir := newTestRemote(t)
+	gitAt(t, repoDir, "2025-03-01T12:00:00Z", "commit", "--quiet", "--allow-empty", "-m", "Third")
+	gitAt(t, repoDir, "2025-03-02T12:00:00Z", "commit", "--quiet", "--allow-empty", "-m", "Fourth")
+	gitAt(t, repoDir, "2025-03-02T12:00:00Z", "tag", "v1.2.0")
+
+	source := NewSource(Config{RemoteURL: remoteURL, MirrorDir: t.TempDir(), FetchInterval: time.Minute})
+	ctx := context.Background()
+
+	commits, more, err := source.ListCommitsBetween(ctx, "team/app", "v1.0.0", "v1.2.0", sources.Page{Number: 1, Size: 2})
+	assert.NoError(t, err)
+	assert.True(t, more)
+	if assert.Len(t, commits, 2) {
+		assert.Equal(t, "Add feature\n\nWith details", commits[0].Message)
+		assert.Equal(t, "Third", commits[1].Message)
+	}
+
+	commits, more, err = source.ListCommitsBetween(ctx, "team/app", "v1.0.0", "v1.2.0", sources.Page{Number: 2, Size: 2})
+	assert.NoError(t, err)
+	assert.False(t, more)
+	if assert.Len(t, commits, 1) {
+		assert.Equal(t, "Fourth", commits[0].Message)
+	}
+
+	_, _, err = source.ListCommitsBetween(ctx, "team/app", "v9.9.9", "v1.2.0", sources.Page{Number: 1, Size: 2})
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestSourceExpandCommitSHA(t *testing.T) {
+	remoteURL, repoDir := newTestRemote(t)
+	source := NewSource(Config{RemoteURL: remoteURL, MirrorDir: t.TempDir(), FetchInterval: time.Minute})
+	ctx := context.Background()
+
+	headSHA := gitAt(t, repoDir, "", "rev-parse", "HEAD")
+	sha, err := source.ExpandCommitSHA(ctx, "team/app", headSHA[:7])
+	assert.NoError(t, err)
+	assert.Equal(t, headSHA, sha)
+
+	// The tree of the commit shares its SHA space, but is not a commit
+	treeSHA := gitAt(t, repoDir, "", "rev-parse", "HEAD^{tree}")
+	_, err = source.ExpandCommitSHA(ctx, "team/app", treeSHA[:12])
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
@@ -0,0 +1,171 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Commit is the subset of a git commit object used by reference-api
+type Commit struct {
+	SHA     string
+	Author  string
+	Date    time.Time
+	Message string
+}
+
+// Tag is a git tag, carrying its own tagger, date and message when it is an annotated tag
+type Tag struct {
+	Name      string
+	Annotated bool
+	Tagger    string
+	Date      time.Time
+	Message   string
+	Commit    *Commit
+}
+
+// readCommit reads the commit that rev resolves to
+func readCommit(ctx context.Context, gitDir, rev string) (*Commit, error) {
+	sha, err := run(ctx, gitDir, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("%w: commit not found for gitRef: %s", sources.ErrNotFound, rev)
+	}
+
+	out, err := run(ctx, gitDir, "show", "--no-patch", "--format=%H%x00%an%x00%aI%x00%B", strings.TrimSpace(sha))
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.SplitN(out, "\x00", 4)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected commit format for %s", rev)
+	}
+
+	date, err := time.Parse(time.RFC3339, fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit date for %s: %v", rev, err)
+	}
+
+	return &Commit{
+		SHA:     fields[0],
+		Author:  fields[1],
+		Date:    date.UTC(),
+		Message: strings.TrimSpace(fields[3]),
+	}, nil
+}
+
+// readTag reads the tag called name along with the commit it points to
+func readTag(ctx context.Context, gitDir, name string) (*Tag, error) {
+	ref := "refs/tags/" + name
+
+	objectType, err := run(ctx, gitDir, "cat-file", "-t", ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tag %s not found", sources.ErrNotFound, name)
+	}
+
+	commit, err := readCommit(ctx, gitDir, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &Tag{Name: name, Commit: commit}
+	if strings.TrimSpace(objectType) != "tag" {
+		return tag, nil
+	}
+
+	// Annotated tags carry their own tagger, date and message
+	out, err := run(ctx, gitDir, "for-each-ref",
+		"--format=%(taggername)%00%(taggerdate:iso-strict)%00%(contents:subject)%00%(contents:body)", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.SplitN(out, "\x00", 4)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected tag format for %s", name)
+	}
+
+	tag.Annotated = true
+	tag.Tagger = fields[0]
+	if date, err := time.Parse(time.RFC3339, fields[1]); err == nil {
+		tag.Date = date.UTC()
+	}
+	tag.Message = strings.TrimSpace(fields[2] + "\n\n" + fields[3])
+	return tag, nil
+}
+
+// tagNames returns the names of every tag, most recently created first
+func tagNames(ctx context.Context, gitDir string) ([]string, error) {
+	out, err := run(ctx, gitDir, "for-each-ref", "--sort=-creatordate", "--format=%(refname:strip=2)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// verifyCommits checks that every rev resolves to a commit
+func verifyCommits(ctx context.Context, gitDir string, revs ...string) error {
+	for _, rev := range revs {
+		if _, err := run(ctx, gitDir, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}"); err != nil {
+			return fmt.Errorf("%w: commit not found for gitRef: %s", sources.ErrNotFound, rev)
+		}
+	}
+	return nil
+}
+
+// compareRevs counts the commits reachable from only one of current and latest
+func compareRevs(ctx context.Context, gitDir, current, latest string) (*sources.Comparison, error) {
+	if err := verifyCommits(ctx, gitDir, current, latest); err != nil {
+		return nil, err
+	}
+
+	out, err := run(ctx, gitDir, "rev-list", "--left-right", "--count", "--end-of-options",
+		current+"^{commit}..."+latest+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+
+	var ahead, behind int
+	if _, err := fmt.Sscan(out, &ahead, &behind); err != nil {
+		return nil, fmt.Errorf("unexpected rev-list output %q: %v", out, err)
+	}
+	return &sources.Comparison{
+		Status:   sources.ComparisonStatus(behind, ahead),
+		BehindBy: behind,
+		AheadBy:  ahead,
+	}, nil
+}
+
+// revsBetween returns the SHAs of the commits reachable from latest but not from current, oldest first
+func revsBetween(ctx context.Context, gitDir, current, latest string) ([]string, error) {
+	if err := verifyCommits(ctx, gitDir, current, latest); err != nil {
+		return nil, err
+	}
+
+	out, err := run(ctx, gitDir, "rev-list", "--reverse", "--end-of-options", latest+"^{commit}", "^"+current+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// commitsWithPrefix returns the full SHAs of the commits whose SHA starts with prefix
+func commitsWithPrefix(ctx context.Context, gitDir, prefix string) ([]string, error) {
+	out, err := run(ctx, gitDir, "rev-parse", "--disambiguate="+strings.ToLower(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	// Trees and blobs share the SHA space of commits
+	var shas []string
+	for _, sha := range strings.Fields(out) {
+		objectType, err := run(ctx, gitDir, "cat-file", "-t", sha)
+		if err == nil && strings.TrimSpace(objectType) == "commit" {
+			shas = append(shas, sha)
+		}
+	}
+	return shas, nil
+}
@@ -0,0 +1,147 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Source resolves references by reading git objects from local mirrors of plain git remotes.
+// Git has no notion of releases, so only tags and commits are resolved.
+type Source struct {
+	mirrors *Mirrors
+}
+
+// NewSource creates a Source mirroring the repositories described by config
+func NewSource(config Config) *Source {
+	return &Source{mirrors: NewMirrors(config)}
+}
+
+// ResolveCurrent resolves the gitRef as a tag or commit of the mirrored repository
+func (s *Source) ResolveCurrent(ctx context.Context, q sources.Query) (*StandardizedEntity, error) {
+	if q.Kind == sources.KindCommit && !sources.IsCommitSHA(q.GitRef) {
+		return nil, fmt.Errorf("%w format: Expected a commit SHA", sources.ErrInvalidRef)
+	}
+	if q.Kind != sources.KindTag && q.Kind != sources.KindCommit {
+		return nil, fmt.Errorf("%w: git repositories have no %ss", sources.ErrNotFound, q.Kind)
+	}
+
+	gitDir, err := s.mirrors.Sync(ctx, q.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Kind == sources.KindTag {
+		tag, err := readTag(ctx, gitDir, q.GitRef)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeTag(tag), nil
+	}
+
+	commit, err := readCommit(ctx, gitDir, q.GitRef)
+	if err != nil {
+		return nil, err
+	}
+	return StandardizeCommit(commit), nil
+}
+
+// ResolveLatest returns the head of the tracked branch for commits and the newest tag admitted by q.Latest otherwise
+func (s *Source) ResolveLatest(ctx context.Context, q sources.Query) (*StandardizedEntity, error) {
+	gitDir, err := s.mirrors.Sync(ctx, q.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Kind == sources.KindCommit {
+		rev := "HEAD"
+		if q.Branch != "" {
+			rev = "refs/heads/" + q.Branch
+		}
+		commit, err := readCommit(ctx, gitDir, rev)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeCommit(commit), nil
+	}
+
+	names, err := tagNames(ctx, gitDir)
+	if err != nil {
+		return nil, err
+	}
+	name := q.Latest.FirstAdmittedTag(names)
+	if name == "" {
+		return nil, nil
+	}
+	tag, err := readTag(ctx, gitDir, name)
+	if err != nil {
+		return nil, err
+	}
+	return StandardizeTag(tag), nil
+}
+
+// ListTagNames returns the names of every tag of repo, most recently created first
+func (s *Source) ListTagNames(ctx context.Context, repo string) ([]string, error) {
+	gitDir, err := s.mirrors.Sync(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return tagNames(ctx, gitDir)
+}
+
+// Compare counts the commits between the current and latest references of the mirrored repository
+func (s *Source) Compare(ctx context.Context, repo, current, latest string) (*sources.Comparison, error) {
+	gitDir, err := s.mirrors.Sync(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return compareRevs(ctx, gitDir, current, latest)
+}
+
+// ExpandCommitSHA returns the full SHA of the only commit of the mirrored repository whose SHA starts with prefix
+func (s *Source) ExpandCommitSHA(ctx context.Context, repo, prefix string) (string, error) {
+	gitDir, err := s.mirrors.Sync(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	shas, err := commitsWithPrefix(ctx, gitDir, prefix)
+	if err != nil {
+		return "", err
+	}
+	switch len(shas) {
+	case 0:
+		return "", fmt.Errorf("%w: commit not found for gitRef: %s", sources.ErrNotFound, prefix)
+	case 1:
+		return shas[0], nil
+	}
+	return "", &sources.AmbiguousRefError{Prefix: prefix, Candidates: shas}
+}
+
+// ListCommitsBetween lists a page of the commits reachable from latest but not from current in the mirrored repository
+func (s *Source) ListCommitsBetween(
+	ctx context.Context, repo, current, latest string, page sources.Page,
+) ([]*StandardizedEntity, bool, error) {
+	gitDir, err := s.mirrors.Sync(ctx, repo)
+	if err != nil {
+		return nil, false, err
+	}
+
+	shas, err := revsBetween(ctx, gitDir, current, latest)
+	if err != nil {
+		return nil, false, err
+	}
+
+	start := min((page.Number-1)*page.Size, len(shas))
+	end := min(start+page.Size, len(shas))
+	commits := make([]*StandardizedEntity, 0, end-start)
+	for _, sha := range shas[start:end] {
+		commit, err := readCommit(ctx, gitDir, sha)
+		if err != nil {
+			return nil, false, err
+		}
+		commits = append(commits, StandardizeCommit(commit))
+	}
+	return commits, end < len(shas), nil
+}
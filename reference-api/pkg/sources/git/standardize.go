@@ -0,0 +1,52 @@
+package git
+
+import (
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// StandardizedEntity is shared by every source
+type StandardizedEntity = sources.StandardizedEntity
+
+// StandardizeCommit converts a Commit into a StandardizedEntity
+func StandardizeCommit(commit *Commit) *StandardizedEntity {
+	if commit == nil {
+		return nil
+	}
+
+	return &StandardizedEntity{
+		Ref:         commit.SHA,
+		Message:     commit.Message,
+		Author:      commit.Author,
+		PublishedAt: commit.Date.String(),
+	}
+}
+
+// StandardizeTag converts a Tag into a StandardizedEntity.
+// Annotated tags use their own message, tagger and date, lightweight tags those of their commit.
+func StandardizeTag(tag *Tag) *StandardizedEntity {
+	if tag == nil {
+		return nil
+	}
+
+	if tag.Annotated {
+		publishedAt := ""
+		if !tag.Date.IsZero() {
+			publishedAt = tag.Date.String()
+		}
+		return &StandardizedEntity{
+			Ref:         tag.Name,
+			Message:     tag.Message,
+			Author:      tag.Tagger,
+			PublishedAt: publishedAt,
+			TagKind:     sources.TagAnnotated,
+		}
+	}
+
+	entity := StandardizeCommit(tag.Commit)
+	if entity == nil {
+		entity = &StandardizedEntity{}
+	}
+	entity.Ref = tag.Name
+	entity.TagKind = sources.TagLightweight
+	return entity
+}
@@ -0,0 +1,66 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Commit is the subset of a Gitea commit used by reference-api
+type Commit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Author  *User  `json:"author"`
+	Commit  *struct {
+		Message string `json:"message"`
+		Author  *struct {
+			Name string     `json:"name"`
+			Date *time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// date returns the author date of the commit
+func (c *Commit) date() time.Time {
+	if c.Commit != nil && c.Commit.Author != nil && c.Commit.Author.Date != nil {
+		return *c.Commit.Author.Date
+	}
+	return time.Time{}
+}
+
+// commitQuery skips the parts of a commit response that reference-api does not use
+var commitQuery = url.Values{
+	"stat":         {"false"},
+	"verification": {"false"},
+	"files":        {"false"},
+}
+
+// FetchCommit fetches a specific commit by its SHA
+func (c *Client) FetchCommit(ctx context.Context, repo, gitRef string) (*Commit, error) {
+	var commit Commit
+	if err := c.get(ctx, repoPath(repo)+"/git/commits/"+url.PathEscape(gitRef), commitQuery, &commit); err != nil {
+		return nil, err
+	}
+	return &commit, nil
+}
+
+// FetchLatestCommit fetches the head commit of branch, or of the default branch when branch is empty
+func (c *Client) FetchLatestCommit(ctx context.Context, repo, branch string) (*Commit, error) {
+	query := url.Values{"limit": {"1"}}
+	for key, values := range commitQuery {
+		query[key] = values
+	}
+	if branch != "" {
+		query.Set("sha", branch)
+	}
+
+	var commits []*Commit
+	if err := c.get(ctx, repoPath(repo)+"/commits", query, &commits); err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits found")
+	}
+	return commits[0], nil
+}
@@ -0,0 +1,122 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Client is a minimal Gitea/Forgejo REST API v1 client
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a Client for the Gitea or Forgejo instance at baseURL, e.g. "https://forgejo.example.com".
+// An empty token results in unauthenticated requests, which only work for public repositories.
+// A nil httpClient uses http.DefaultClient.
+func NewClient(baseURL, token string, httpClient *http.Client) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/") + "/api/v1",
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// pageSize is the number of items requested per page of a listing, the default maximum of Gitea
+const pageSize = 50
+
+// repoPath returns the API path of a repository addressed as "owner/repo"
+func repoPath(repo string) string {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repoName)
+}
+
+// get decodes the JSON response of a GET request into out.
+// A 404 from Gitea is returned as an error wrapping sources.ErrNotFound.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "token "+c.token)
+	}
+
+	if err := sources.GetJSON(ctx, c.httpClient, endpoint, header, out); err != nil {
+		return fmt.Errorf("Gitea %w", err)
+	}
+	return nil
+}
+
+// findPaged walks the pages of the listing at path until match accepts an item, returning the zero value when none does
+func findPaged[T any](ctx context.Context, c *Client, path string, match func(T) bool) (T, error) {
+	var zero T
+	query := url.Values{"limit": {strconv.Itoa(pageSize)}}
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var items []T
+		if err := c.get(ctx, path, query, &items); err != nil {
+			return zero, err
+		}
+		for _, item := range items {
+			if match(item) {
+				return item, nil
+			}
+		}
+		if len(items) < pageSize {
+			return zero, nil
+		}
+	}
+}
+
+// FetchLatestReference fetches the most recent reference (release or tag) admitted by policy by comparing dates
+// Returns the latest as a StandardizedEntity, preferring releases over tags when dates are equal
+func (c *Client) FetchLatestReference(
+	ctx context.Context, repo string, policy sources.LatestPolicy,
+) (*StandardizedEntity, error) {
+	latestRelease, err := c.FetchLatestRelease(ctx, repo, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	latestTag, err := c.FetchLatestTag(ctx, repo, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch commit for the tag to get date
+	var latestTagCommit *Commit
+	if latestTag != nil {
+		latestTagCommit, err = c.fetchCommitForTag(ctx, repo, latestTag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Compare dates and return the most recent
+	if latestRelease != nil && latestTagCommit != nil {
+		if latestTagCommit.date().After(latestRelease.date()) {
+			return StandardizeTag(latestTag, latestTagCommit), nil
+		}
+		return StandardizeRelease(latestRelease), nil
+	}
+
+	// Return whichever is available
+	if latestRelease != nil {
+		return StandardizeRelease(latestRelease), nil
+	}
+	if latestTag != nil {
+		return StandardizeTag(latestTag, latestTagCommit), nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,246 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/sourcestest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testToken = "test-token"
+	repoBase  = "/api/v1/repos/team/app"
+
+	tagSHA  = "fedcba9876543210fedcba9876543210fedcba98"
+	headSHA = "abc123def4567890abc123def4567890abc123de"
+)
+
+// testAPI is a stand-in of the Gitea API
+var testAPI = sourcestest.API{
+	Header:        "Authorization",
+	Authorization: "token " + testToken,
+	NotFound:      `{"message":"not found"}`,
+}
+
+// testCommit returns a commit response for sha authored at date
+func testCommit(sha, message, date string) string {
+	return fmt.Sprintf(`{
+		"sha": %q,
+		"html_url": "https://forgejo.example.com/team/app/commit/%s",
+		"author": {"login": "commitauthor"},
+		"commit": {"message": %q, "author": {"name": "Commit Author", "date": %q}}
+	}`, sha, sha, message, date)
+}
+
+const (
+	testRelease = `{
+		"tag_name": "v1.0.0",
+		"body": "## What's New\n- Feature A",
+		"html_url": "https://forgejo.example.com/team/app/releases/tag/v1.0.0",
+		"published_at": "2025-06-16T12:00:00Z",
+		"author": {"login": "releaseauthor"}
+	}`
+	testTag = `{"name": "v1.1.0", "id": "` + tagSHA + `", "message": "Tagged change\n", "commit": {"sha": "` + tagSHA + `"}}`
+
+	testAnnotatedTag = `{
+		"name": "v1.2.0",
+		"id": "0123456789abcdef0123456789abcdef01234567",
+		"message": "Release 1.2.0\n",
+		"commit": {"sha": "` + tagSHA + `"}
+	}`
+)
+
+func TestSourceResolveCurrent(t *testing.T) {
+	server := testAPI.ServeJSON(t, map[string]string{
+		repoBase + "/releases/tags/v1.0.0":  testRelease,
+		repoBase + "/tags/v1.1.0":           testTag,
+		repoBase + "/tags/v1.2.0":           testAnnotatedTag,
+		repoBase + "/git/commits/" + tagSHA: testCommit(tagSHA, "Tagged change", "2025-07-01T08:00:00Z"),
+	})
+	source := NewSource(server.URL, testToken, nil)
+
+	tests := []struct {
+		name    string
+		kind    sources.Kind
+		gitRef  string
+		want    *StandardizedEntity
+		wantErr error
+	}{
+		{
+			name:   "Release",
+			kind:   sources.KindRelease,
+			gitRef: "v1.0.0",
+			want: &StandardizedEntity{
+				Ref:         "v1.0.0",
+				URL:         "https://forgejo.example.com/team/app/releases/tag/v1.0.0",
+				Message:     "## What's New\n- Feature A",
+				Author:      "releaseauthor",
+				PublishedAt: "2025-06-16 12:00:00 +0000 UTC",
+			},
+		},
+		{
+			name:   "Lightweight tag uses the commit it points to, whose message it repeats",
+			kind:   sources.KindTag,
+			gitRef: "v1.1.0",
+			want: &StandardizedEntity{
+				Ref:         "v1.1.0",
+				URL:         "https://forgejo.example.com/team/app/commit/" + tagSHA,
+				Message:     "Tagged change",
+				Author:      "commitauthor",
+				PublishedAt: "2025-07-01 08:00:00 +0000 UTC",
+				TagKind:     sources.TagLightweight,
+			},
+		},
+		{
+			name:   "Annotated tag carries its own message",
+			kind:   sources.KindTag,
+			gitRef: "v1.2.0",
+			want: &StandardizedEntity{
+				Ref:         "v1.2.0",
+				URL:         "https://forgejo.example.com/team/app/commit/" + tagSHA,
+				Message:     "Release 1.2.0",
+				Author:      "commitauthor",
+				PublishedAt: "2025-07-01 08:00:00 +0000 UTC",
+				TagKind:     sources.TagAnnotated,
+			},
+		},
+		{
+			name:   "Commit",
+			kind:   sources.KindCommit,
+			gitRef: tagSHA,
+			want: &StandardizedEntity{
+				Ref:         tagSHA,
+				URL:         "https://forgejo.example.com/team/app/commit/" + tagSHA,
+				Message:     "Tagged change",
+				Author:      "commitauthor",
+				PublishedAt: "2025-07-01 08:00:00 +0000 UTC",
+			},
+		},
+		{
+			name:    "Missing release falls through",
+			kind:    sources.KindRelease,
+			gitRef:  "v1.1.0",
+			wantErr: sources.ErrNotFound,
+		},
+		{
+			name:    "Missing tag falls through",
+			kind:    sources.KindTag,
+			gitRef:  headSHA,
+			wantErr: sources.ErrNotFound,
+		},
+		{
+			name:    "Invalid commit SHA",
+			kind:    sources.KindCommit,
+			gitRef:  "v9.9.9",
+			wantErr: sources.ErrInvalidRef,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := source.ResolveCurrent(context.Background(), sources.Query{
+				Repo:   "team/app",
+				GitRef: tt.gitRef,
+				Kind:   tt.kind,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSourceResolveLatest(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		kind      sources.Kind
+		wantRef   string
+	}{
+		{
+			name: "Tag newer than release",
+			responses: map[string]string{
+				repoBase + "/releases":              "[" + testRelease + "]",
+				repoBase + "/tags":                  "[" + testTag + "]",
+				repoBase + "/git/commits/" + tagSHA: testCommit(tagSHA, "Tagged change", "2025-07-01T08:00:00Z"),
+			},
+			kind:    sources.KindRelease,
+			wantRef: "v1.1.0",
+		},
+		{
+			name: "Release newer than tag",
+			responses: map[string]string{
+				repoBase + "/releases":              "[" + testRelease + "]",
+				repoBase + "/tags":                  "[" + testTag + "]",
+				repoBase + "/git/commits/" + tagSHA: testCommit(tagSHA, "Tagged change", "2025-01-01T08:00:00Z"),
+			},
+			kind:    sources.KindTag,
+			wantRef: "v1.0.0",
+		},
+		{
+			name: "Prerelease and draft releases are skipped",
+			responses: map[string]string{
+				repoBase + "/releases": `[
+					{"tag_name": "v3.0.0", "draft": true},
+					{"tag_name": "v2.0.0", "prerelease": true},
+					` + testRelease + `
+				]`,
+				repoBase + "/tags": "[]",
+			},
+			kind:    sources.KindRelease,
+			wantRef: "v1.0.0",
+		},
+		{
+			name: "Head of the default branch for commits",
+			responses: map[string]string{
+				repoBase + "/commits": "[" + testCommit(headSHA, "Latest change", "2025-08-01T08:00:00Z") + "]",
+			},
+			kind:    sources.KindCommit,
+			wantRef: headSHA,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testAPI.ServeJSON(t, tt.responses)
+			source := NewSource(server.URL, testToken, nil)
+
+			got, err := source.ResolveLatest(context.Background(), sources.Query{
+				Repo: "team/app",
+				Kind: tt.kind,
+			})
+			assert.NoError(t, err)
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tt.wantRef, got.Ref)
+			}
+		})
+	}
+}
+
+func TestClientListTagNames(t *testing.T) {
+	server := sourcestest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, repoBase+"/tags", r.URL.Path)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		// 120 tags, served 50 per page
+		tags := make([]string, 0, pageSize)
+		for i := (page - 1) * pageSize; i < min(page*pageSize, 120); i++ {
+			tags = append(tags, fmt.Sprintf(`{"name": "v1.%d.0"}`, i))
+		}
+		_, _ = fmt.Fprint(w, "["+strings.Join(tags, ",")+"]")
+	}))
+
+	names, err := NewClient(server.URL, "", nil).ListTagNames(context.Background(), "team/app")
+	assert.NoError(t, err)
+	assert.Len(t, names, 120)
+	assert.Equal(t, "v1.119.0", names[119])
+}
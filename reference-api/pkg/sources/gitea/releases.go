@@ -0,0 +1,63 @@
+package gitea
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Release is the subset of a Gitea release used by reference-api
+type Release struct {
+	TagName     string     `json:"tag_name"`
+	Body        string     `json:"body"`
+	HTMLURL     string     `json:"html_url"`
+	Draft       bool       `json:"draft"`
+	Prerelease  bool       `json:"prerelease"`
+	CreatedAt   *time.Time `json:"created_at"`
+	PublishedAt *time.Time `json:"published_at"`
+	Author      *User      `json:"author"`
+}
+
+// User is the subset of a Gitea user used by reference-api
+type User struct {
+	Login string `json:"login"`
+}
+
+// date returns when the release was published, falling back to its creation date
+func (r *Release) date() time.Time {
+	if r.PublishedAt != nil {
+		return *r.PublishedAt
+	}
+	if r.CreatedAt != nil {
+		return *r.CreatedAt
+	}
+	return time.Time{}
+}
+
+// FetchRelease fetches the release published for the gitRef tag
+func (c *Client) FetchRelease(ctx context.Context, repo, gitRef string) (*Release, error) {
+	var release Release
+	if err := c.get(ctx, repoPath(repo)+"/releases/tags/"+url.PathEscape(gitRef), nil, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// FetchLatestRelease fetches the newest release admitted by policy, or nil if the repository has none
+func (c *Client) FetchLatestRelease(ctx context.Context, repo string, policy sources.LatestPolicy) (*Release, error) {
+	return findPaged(ctx, c, repoPath(repo)+"/releases", func(release *Release) bool {
+		return policy.AdmitsRelease(release.TagName, release.Prerelease, release.Draft)
+	})
+}
+
+// ListReleases lists every release of repo, newest first
+func (c *Client) ListReleases(ctx context.Context, repo string) ([]*Release, error) {
+	var releases []*Release
+	_, err := findPaged(ctx, c, repoPath(repo)+"/releases", func(release *Release) bool {
+		releases = append(releases, release)
+		return false
+	})
+	return releases, err
+}
@@ -0,0 +1,91 @@
+package gitea
+
+import (
+	"strings"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// StandardizedEntity is shared by every source
+type StandardizedEntity = sources.StandardizedEntity
+
+// StandardizeCommit converts a Commit into a StandardizedEntity
+func StandardizeCommit(commit *Commit) *StandardizedEntity {
+	if commit == nil {
+		return nil
+	}
+
+	entity := &StandardizedEntity{
+		Ref: commit.SHA,
+		URL: commit.HTMLURL,
+	}
+
+	if commit.Commit != nil {
+		entity.Message = commit.Commit.Message
+		if commit.Commit.Author != nil {
+			entity.Author = commit.Commit.Author.Name
+		}
+	}
+
+	// Prefer the login of the linked account over the git author name
+	if commit.Author != nil && commit.Author.Login != "" {
+		entity.Author = commit.Author.Login
+	}
+
+	if date := commit.date(); !date.IsZero() {
+		entity.PublishedAt = date.String()
+	}
+
+	return entity
+}
+
+// StandardizeRelease converts a Release into a StandardizedEntity
+func StandardizeRelease(release *Release) *StandardizedEntity {
+	if release == nil {
+		return nil
+	}
+
+	author := ""
+	if release.Author != nil {
+		author = release.Author.Login
+	}
+
+	publishedAt := ""
+	if date := release.date(); !date.IsZero() {
+		publishedAt = date.String()
+	}
+
+	return &StandardizedEntity{
+		Ref:         release.TagName,
+		URL:         release.HTMLURL,
+		Message:     release.Body,
+		Author:      author,
+		PublishedAt: publishedAt,
+		Prerelease:  release.Prerelease,
+		Draft:       release.Draft,
+	}
+}
+
+// StandardizeTag converts a Tag and its associated Commit into a StandardizedEntity
+func StandardizeTag(tag *Tag, commit *Commit) *StandardizedEntity {
+	if tag == nil {
+		return nil
+	}
+
+	// If we have the commit details, use them for richer information
+	entity := StandardizeCommit(commit)
+	if entity == nil {
+		entity = &StandardizedEntity{}
+	}
+	entity.Ref = tag.Name
+	entity.TagKind = sources.TagLightweight
+
+	// Only annotated tags carry a message of their own, lightweight ones repeat that of their commit
+	if tag.Annotated() {
+		entity.TagKind = sources.TagAnnotated
+		if tag.Message != "" {
+			entity.Message = strings.TrimSpace(tag.Message)
+		}
+	}
+	return entity
+}
@@ -0,0 +1,58 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Tag is the subset of a Gitea repository tag used by reference-api
+type Tag struct {
+	Name    string `json:"name"`
+	ID      string `json:"id"`      // SHA of the tag object of annotated tags, else of the commit
+	Message string `json:"message"` // Message of the tag object of annotated tags, else of the commit
+	Commit  *struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// Annotated reports whether the tag is an annotated tag, pointing to a tag object of its own
+func (t *Tag) Annotated() bool {
+	return t.ID != "" && t.Commit != nil && t.ID != t.Commit.SHA
+}
+
+// FetchTag fetches a specific repository tag by name
+func (c *Client) FetchTag(ctx context.Context, repo, gitRef string) (*Tag, error) {
+	var tag Tag
+	if err := c.get(ctx, repoPath(repo)+"/tags/"+url.PathEscape(gitRef), nil, &tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FetchLatestTag fetches the newest tag admitted by policy, or nil if the repository has none
+func (c *Client) FetchLatestTag(ctx context.Context, repo string, policy sources.LatestPolicy) (*Tag, error) {
+	return findPaged(ctx, c, repoPath(repo)+"/tags", func(tag *Tag) bool {
+		return policy.AdmitsTag(tag.Name)
+	})
+}
+
+// fetchCommitForTag fetches the commit that a tag points to
+func (c *Client) fetchCommitForTag(ctx context.Context, repo string, tag *Tag) (*Commit, error) {
+	if tag == nil || tag.Commit == nil || tag.Commit.SHA == "" {
+		return nil, fmt.Errorf("invalid tag data")
+	}
+	return c.FetchCommit(ctx, repo, tag.Commit.SHA)
+}
+
+// ListTagNames returns the names of every tag of repo, newest first
+func (c *Client) ListTagNames(ctx context.Context, repo string) ([]string, error) {
+	var names []string
+	_, err := findPaged(ctx, c, repoPath(repo)+"/tags", func(tag *Tag) bool {
+		names = append(names, tag.Name)
+		return false
+	})
+	return names, err
+}
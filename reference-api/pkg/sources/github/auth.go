@@ -0,0 +1,242 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// AuthMode selects how requests to the GitHub API are authenticated
+type AuthMode string
+
+const (
+	AuthModeNone  AuthMode = "none"  // Unauthenticated, limited to 60 requests per hour
+	AuthModeApp   AuthMode = "app"   // GitHub App installation tokens
+	AuthModeToken AuthMode = "token" // Static personal access or fine-grained token
+)
+
+// AuthProvider supplies the token used to authenticate requests for a repository
+type AuthProvider interface {
+	// Token returns the token for repo, or "" for unauthenticated access
+	Token(ctx context.Context, repo string) (string, error)
+	// Mode returns the authentication mode the provider implements
+	Mode() AuthMode
+}
+
+// noAuth leaves requests unauthenticated
+type noAuth struct{}
+
+func (noAuth) Token(context.Context, string) (string, error) { return "", nil }
+func (noAuth) Mode() AuthMode                                { return AuthModeNone }
+
+// staticToken authenticates every request with the same token
+type staticToken string
+
+func (t staticToken) Token(context.Context, string) (string, error) { return string(t), nil }
+func (staticToken) Mode() AuthMode                                  { return AuthModeToken }
+
+// fileToken authenticates with the token held in a file, re-reading it whenever the file changes
+// so that rotated secrets are picked up without a restart
+type fileToken struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	token   string
+}
+
+func (f *fileToken) Token(context.Context, string) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", &credentialError{fmt.Errorf("failed to read token file: %v", err)}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.token != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.token, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", &credentialError{fmt.Errorf("failed to read token file: %v", err)}
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", &credentialError{fmt.Errorf("token file %s is empty", f.path)}
+	}
+	f.token, f.modTime, f.size = token, info.ModTime(), info.Size()
+	return f.token, nil
+}
+
+func (*fileToken) Mode() AuthMode { return AuthModeToken }
+
+// unusableApp stands in for a GitHub App whose private key cannot be used, leaving requests unauthenticated while
+// keeping the configured mode, so that Status reports the key error instead of a healthy unauthenticated source
+type unusableApp struct {
+	err error
+}
+
+func (a unusableApp) Token(context.Context, string) (string, error) {
+	return "", &credentialError{a.err}
+}
+func (unusableApp) Mode() AuthMode                 { return AuthModeApp }
+func (a unusableApp) Verify(context.Context) error { return a.err }
+
+// Mode returns AuthModeApp
+func (m *TokenManager) Mode() AuthMode { return AuthModeApp }
+
+// Verify checks that the App ID and private key are accepted by GitHub
+func (m *TokenManager) Verify(ctx context.Context) error {
+	client, err := m.appClient()
+	if err != nil {
+		return err
+	}
+	app, _, err := client.Apps.Get(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to authenticate as GitHub App %s: %v", m.appID, err)
+	}
+	if id := strconv.FormatInt(app.GetID(), 10); id != m.appID {
+		return fmt.Errorf("private key belongs to GitHub App %s, not the configured %s", id, m.appID)
+	}
+	return nil
+}
+
+// authMode returns the configured authentication mode. Configurations predating explicit modes
+// authenticate as a GitHub App when a private key is set, with their token when one is set, and are
+// unauthenticated otherwise.
+func (c Config) authMode() AuthMode {
+	switch {
+	case c.AuthMode != "":
+		return c.AuthMode
+	case c.PrivateKeyPath != "":
+		return AuthModeApp
+	case c.Token != "" || c.TokenFile != "":
+		return AuthModeToken
+	}
+	return AuthModeNone
+}
+
+// newAuthProvider creates the AuthProvider for the configured authentication mode.
+// newClient returns unauthenticated clients for the GitHub API.
+func newAuthProvider(config Config, newClient func() (*github.Client, error)) (AuthProvider, error) {
+	switch mode := config.authMode(); mode {
+	case AuthModeNone:
+		return noAuth{}, nil
+	case AuthModeToken:
+		if config.TokenFile != "" {
+			provider := &fileToken{path: config.TokenFile}
+			if _, err := provider.Token(context.Background(), ""); err != nil {
+				return nil, err
+			}
+			return provider, nil
+		}
+		if config.Token == "" {
+			return nil, fmt.Errorf("auth mode %q requires a token or a token file", mode)
+		}
+		return staticToken(config.Token), nil
+	case AuthModeApp:
+		if config.AppID == "" || config.PrivateKeyPath == "" {
+			return nil, fmt.Errorf("auth mode %q requires an App ID and a private key path", mode)
+		}
+		privateKey, err := parsePrivateKey(config.PrivateKeyPath)
+		if err != nil {
+			if config.StrictAuth {
+				return nil, err
+			}
+			log.Printf("WARNING: %v. Requests are unauthenticated until the private key is fixed.", err)
+			return unusableApp{err}, nil
+		}
+		return NewTokenManager(config.AppID, privateKey, newClient), nil
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", mode)
+	}
+}
+
+// credentialError marks failures of the credentials themselves, as opposed to failures to get a token for a single
+// repository, such as one the GitHub App is not installed on
+type credentialError struct {
+	err error
+}
+
+func (e *credentialError) Error() string { return e.err.Error() }
+func (e *credentialError) Unwrap() error { return e.err }
+
+// authToken returns the token for repo from the auth provider, recording the outcome for Status.
+// Failures for a single repository are not recorded, so that they don't make the whole source unhealthy.
+func (s *Source) authToken(ctx context.Context, repo string) (string, error) {
+	token, err := s.auth.Token(ctx, repo)
+	var credErr *credentialError
+	if err == nil || errors.As(err, &credErr) {
+		s.mu.Lock()
+		s.authErr = err
+		s.mu.Unlock()
+	}
+	return token, err
+}
+
+// Verify checks the configured credentials against the GitHub API.
+// Failures are reported by Status, and returned when auth is strict so that startup can be refused.
+func (s *Source) Verify(ctx context.Context) error {
+	err := s.verifyAuth(ctx)
+	s.mu.Lock()
+	s.authErr = err
+	s.mu.Unlock()
+	if err != nil && !s.config.StrictAuth {
+		log.Printf("WARNING: GitHub credentials could not be verified: %v", err)
+		return nil
+	}
+	return err
+}
+
+// verifyAuth checks the credentials of the auth provider with a request that needs no repository access
+func (s *Source) verifyAuth(ctx context.Context) error {
+	if v, ok := s.auth.(sources.Verifier); ok {
+		return v.Verify(ctx)
+	}
+	if s.auth.Mode() == AuthModeNone {
+		return nil
+	}
+
+	token, err := s.auth.Token(ctx, "")
+	if err != nil {
+		return err
+	}
+	client, err := s.newClient()
+	if err != nil {
+		return err
+	}
+	// The rate limit endpoint accepts any valid token without counting against the limit
+	if _, _, err := client.WithAuthToken(token).RateLimit.Get(ctx); err != nil {
+		return fmt.Errorf("failed to authenticate with the configured token: %v", err)
+	}
+	return nil
+}
+
+// Status reports the auth mode in use and whether its credentials last worked
+func (s *Source) Status() sources.Status {
+	s.mu.Lock()
+	err := s.authErr
+	s.mu.Unlock()
+
+	status := sources.Status{
+		Type:     "github",
+		AuthMode: string(s.auth.Mode()),
+		Strict:   s.config.StrictAuth,
+		Healthy:  err == nil,
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
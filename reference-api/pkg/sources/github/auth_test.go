@@ -0,0 +1,270 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/sourcestest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSourceAuthMode(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		config  Config
+		want    AuthMode
+		wantErr string
+	}{
+		{
+			name:   "Unauthenticated by default",
+			config: Config{},
+			want:   AuthModeNone,
+		},
+		{
+			name:   "Token",
+			config: Config{AuthMode: AuthModeToken, Token: "ghp_test"},
+			want:   AuthModeToken,
+		},
+		{
+			name:   "Token file",
+			config: Config{AuthMode: AuthModeToken, TokenFile: tokenFile},
+			want:   AuthModeToken,
+		},
+		{
+			name:   "Token alone selects token mode",
+			config: Config{Token: "ghp_test"},
+			want:   AuthModeToken,
+		},
+		{
+			name:   "Token file alone selects token mode",
+			config: Config{TokenFile: tokenFile},
+			want:   AuthModeToken,
+		},
+		{
+			name:   "Unreadable private key keeps app mode",
+			config: Config{AppID: "12345", PrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem")},
+			want:   AuthModeApp,
+		},
+		{
+			name: "Strict auth refuses an unreadable private key",
+			config: Config{
+				AppID: "12345", PrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem"), StrictAuth: true,
+			},
+			wantErr: "private key not found",
+		},
+		{
+			name:    "Token mode without a token",
+			config:  Config{AuthMode: AuthModeToken},
+			wantErr: `auth mode "token" requires a token or a token file`,
+		},
+		{
+			name:    "Missing token file",
+			config:  Config{AuthMode: AuthModeToken, TokenFile: filepath.Join(t.TempDir(), "missing")},
+			wantErr: "failed to read token file",
+		},
+		{
+			name:    "App mode without an App ID",
+			config:  Config{AuthMode: AuthModeApp, PrivateKeyPath: "key.pem"},
+			wantErr: `auth mode "app" requires an App ID and a private key path`,
+		},
+		{
+			name:    "Unknown mode",
+			config:  Config{AuthMode: "oauth"},
+			wantErr: `unknown auth mode "oauth"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewSource(tt.config)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, source.AuthMode())
+		})
+	}
+}
+
+func TestFileTokenReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+	provider := &fileToken{path: path}
+
+	token, err := provider.Token(context.Background(), "org/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", token)
+
+	require.NoError(t, os.WriteFile(path, []byte("second\n"), 0o600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	token, err = provider.Token(context.Background(), "org/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, "second", token)
+}
+
+func TestSourceSendsStaticToken(t *testing.T) {
+	var authorization string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/org/repo/commits/"+testSHA, func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		sourcestest.JSON(testCommitJSON(testSHA, "Fix bug", "2025-06-15T10:30:00Z"))(w, r)
+	})
+	server := sourcestest.NewServer(t, mux)
+
+	source, err := NewSource(Config{BaseURL: server.URL, AuthMode: AuthModeToken, Token: "ghp_test"})
+	require.NoError(t, err)
+
+	_, err = source.ResolveCurrent(context.Background(), sources.Query{
+		Repo:   "org/repo",
+		GitRef: testSHA,
+		Kind:   sources.KindCommit,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer ghp_test", authorization)
+}
+
+// writeTestKey writes a new RSA private key to a temporary PEM file and returns its path
+func writeTestKey(t *testing.T) string {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	require.NoError(t, os.WriteFile(path, keyPEM, 0o600))
+	return path
+}
+
+func TestSourceVerify(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/app", sourcestest.JSON(`{"id": 12345, "slug": "reference-api"}`))
+	mux.HandleFunc("/api/v3/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ghp_valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message": "Bad credentials"}`))
+			return
+		}
+		sourcestest.JSON(`{"resources": {}}`)(w, r)
+	})
+	server := sourcestest.NewServer(t, mux)
+	keyPath := writeTestKey(t)
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+		healthy bool
+	}{
+		{
+			name:    "Unauthenticated",
+			config:  Config{},
+			healthy: true,
+		},
+		{
+			name:    "GitHub App",
+			config:  Config{AppID: "12345", PrivateKeyPath: keyPath, StrictAuth: true},
+			healthy: true,
+		},
+		{
+			name:    "Strict GitHub App with the wrong App ID",
+			config:  Config{AppID: "999", PrivateKeyPath: keyPath, StrictAuth: true},
+			wantErr: "private key belongs to GitHub App 12345, not the configured 999",
+		},
+		{
+			name:    "GitHub App with the wrong App ID only reports unhealthy",
+			config:  Config{AppID: "999", PrivateKeyPath: keyPath},
+			healthy: false,
+		},
+		{
+			name:    "GitHub App with an unreadable private key only reports unhealthy",
+			config:  Config{AppID: "12345", PrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem")},
+			healthy: false,
+		},
+		{
+			name:    "Token",
+			config:  Config{AuthMode: AuthModeToken, Token: "ghp_valid", StrictAuth: true},
+			healthy: true,
+		},
+		{
+			name:    "Strict invalid token",
+			config:  Config{AuthMode: AuthModeToken, Token: "ghp_revoked", StrictAuth: true},
+			wantErr: "failed to authenticate with the configured token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.BaseURL = server.URL
+			source, err := NewSource(tt.config)
+			require.NoError(t, err)
+
+			err = source.Verify(context.Background())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.False(t, source.Status().Healthy)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.healthy, source.Status().Healthy)
+		})
+	}
+}
+
+func TestSourceStatusUnusablePrivateKey(t *testing.T) {
+	server := sourcestest.NewServer(t, sourcestest.JSON(testCommitJSON(testSHA, "Fix bug", "2025-06-15T10:30:00Z")))
+	source, err := NewSource(Config{
+		BaseURL: server.URL, AppID: "12345", PrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem"),
+	})
+	require.NoError(t, err)
+	assert.NoError(t, source.Verify(context.Background()))
+
+	// Requests go unauthenticated, while the status keeps the configured mode and reports the key error
+	query := sources.Query{Repo: "org/repo", GitRef: testSHA, Kind: sources.KindCommit}
+	_, err = source.ResolveCurrent(context.Background(), query)
+	assert.NoError(t, err)
+	status := source.Status()
+	assert.Equal(t, string(AuthModeApp), status.AuthMode)
+	assert.False(t, status.Healthy)
+	assert.Contains(t, status.Error, "private key not found")
+}
+
+func TestSourceStrictAuthFailsRequests(t *testing.T) {
+	server := sourcestest.NewServer(t, sourcestest.JSON(testCommitJSON(testSHA, "Fix bug", "2025-06-15T10:30:00Z")))
+	query := sources.Query{Repo: "org/repo", GitRef: testSHA, Kind: sources.KindCommit}
+
+	for _, strict := range []bool{false, true} {
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenFile, []byte("ghp_test"), 0o600))
+		source, err := NewSource(Config{
+			BaseURL: server.URL, AuthMode: AuthModeToken, TokenFile: tokenFile, StrictAuth: strict,
+		})
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(tokenFile))
+
+		_, err = source.ResolveCurrent(context.Background(), query)
+		if strict {
+			assert.ErrorContains(t, err, "failed to authenticate in token mode")
+		} else {
+			assert.NoError(t, err)
+		}
+
+		status := source.Status()
+		assert.Equal(t, "token", status.AuthMode)
+		assert.False(t, status.Healthy)
+		assert.Contains(t, status.Error, "failed to read token file")
+	}
+}
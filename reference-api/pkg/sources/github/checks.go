@@ -0,0 +1,61 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// ChecksFor lists the check runs and commit statuses reported for ref, a commit SHA, tag or release
+func (s *Source) ChecksFor(ctx context.Context, repo, ref string) ([]*sources.Check, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	client, err := s.NewGithubClient(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, resp, err := listPaged(func(opts *github.ListOptions) ([]*github.CheckRun, *github.Response, error) {
+		result, resp, err := client.Checks.ListCheckRunsForRef(ctx, owner, repoName, ref, &github.ListCheckRunsOptions{
+			ListOptions: *opts,
+		})
+		if err != nil {
+			return nil, resp, err
+		}
+		return result.CheckRuns, resp, nil
+	})
+	if err != nil {
+		return nil, checksError(resp, ref, err)
+	}
+
+	statuses, resp, err := listPaged(func(opts *github.ListOptions) ([]*github.RepoStatus, *github.Response, error) {
+		combined, resp, err := client.Repositories.GetCombinedStatus(ctx, owner, repoName, ref, opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		return combined.Statuses, resp, nil
+	})
+	if err != nil {
+		return nil, checksError(resp, ref, err)
+	}
+
+	checks := make([]*sources.Check, 0, len(runs)+len(statuses))
+	for _, run := range runs {
+		checks = append(checks, StandardizeCheckRun(run))
+	}
+	for _, status := range statuses {
+		checks = append(checks, StandardizeStatus(status))
+	}
+	return checks, nil
+}
+
+// checksError wraps a failure to list the checks of ref
+func checksError(resp *github.Response, ref string, err error) error {
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: commit not found for gitRef: %s", sources.ErrNotFound, ref)
+	}
+	return fmt.Errorf("error listing checks: %v", err)
+}
@@ -2,13 +2,13 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"regexp"
 	"strings"
 
 	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
 )
 
 type MergedCommits struct {
@@ -17,83 +17,67 @@ type MergedCommits struct {
 }
 
 // FetchCommit fetches a specific commit by its Git reference
-func FetchCommit(repo, gitRef string) (*github.RepositoryCommit, error) {
-	owner, repoName, _ := strings.Cut(repo, "/")
-	client := NewGithubClient((repo))
-	ctx := context.Background()
-	commit, _, err := client.Repositories.GetCommit(ctx, owner, repoName, gitRef, nil)
-	if err != nil {
-		return nil, err
+func (s *Source) FetchCommit(ctx context.Context, repo, gitRef string) (*github.RepositoryCommit, error) {
+	if !sources.IsCommitSHA(gitRef) {
+		return nil, fmt.Errorf("%w format: Expected a commit SHA", sources.ErrInvalidRef)
 	}
-	return commit, nil
-}
 
-// FetchLatestCommit fetches the latest commit from the repository
-func FetchLatestCommit(repo string) (*github.RepositoryCommit, error) {
 	owner, repoName, _ := strings.Cut(repo, "/")
-	client := NewGithubClient((repo))
-	ctx := context.Background()
-	commits, _, err := client.Repositories.ListCommits(ctx, owner, repoName, nil)
+	client, err := s.NewGithubClient(ctx, repo)
 	if err != nil {
 		return nil, err
 	}
-
-	if len(commits) > 0 {
-		return commits[0], nil
+	commit, resp, err := client.Repositories.GetCommit(ctx, owner, repoName, gitRef, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: commit not found for gitRef: %s", sources.ErrNotFound, gitRef)
+		}
+		return nil, fmt.Errorf("error fetching commit: %v", err)
 	}
-
-	return nil, fmt.Errorf("no commits found")
+	return commit, nil
 }
 
-// FetchCommits fetches both the latest commit and the one matching the Git reference (gitRef)
-func FetchCommits(repo, gitRef string) (*StandardizedOutput, int, error) {
-	currentCommit, err := FetchCommit(repo, gitRef)
+// ExpandCommitSHA returns the full SHA of the only commit whose SHA starts with prefix.
+// GitHub does not list the commits an ambiguous prefix matches.
+func (s *Source) ExpandCommitSHA(ctx context.Context, repo, prefix string) (string, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	client, err := s.NewGithubClient(ctx, repo)
 	if err != nil {
-		log.Printf("Error fetching current commit: %v", err)
-		if strings.Contains(err.Error(), "404") {
-			return nil, http.StatusNotFound, fmt.Errorf("commit not found for gitRef: %s", gitRef)
-		}
-		return nil, http.StatusInternalServerError, fmt.Errorf("error fetching commit: %v", err)
+		return "", err
 	}
-
-	latestCommit, err := FetchLatestCommit(repo)
+	sha, resp, err := client.Repositories.GetCommitSHA1(ctx, owner, repoName, prefix, "")
 	if err != nil {
-		log.Printf("Error fetching latest commit: %v", err)
-		latestCommit = nil // Allow partial results
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && strings.Contains(strings.ToLower(errResp.Message), "ambiguous") {
+			return "", &sources.AmbiguousRefError{Prefix: prefix}
+		}
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+			return "", fmt.Errorf("%w: commit not found for gitRef: %s", sources.ErrNotFound, prefix)
+		}
+		return "", fmt.Errorf("error expanding commit SHA: %v", err)
 	}
-
-	// Standardize the commit response
-	return &StandardizedOutput{
-		Latest:  StandardizeCommit(latestCommit),
-		Current: StandardizeCommit(currentCommit),
-	}, http.StatusOK, nil
+	return sha, nil
 }
 
-// CommitsHandler handles the API endpoint for fetching the latest and current commits
-func CommitsHandler(w http.ResponseWriter, r *http.Request) {
-	repo := r.URL.Query().Get("repo")
-	gitRef := r.URL.Query().Get("gitRef")
-	if repo == "" {
-		errorEncoder(w, http.StatusBadRequest, "Missing 'repo' query parameter")
-		return
-	}
-	if gitRef == "" {
-		errorEncoder(w, http.StatusBadRequest, "Missing 'gitRef' query parameter")
-		return
+// FetchLatestCommit fetches the head commit of branch, or of the default branch when branch is empty
+func (s *Source) FetchLatestCommit(ctx context.Context, repo, branch string) (*github.RepositoryCommit, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	client, err := s.NewGithubClient(ctx, repo)
+	if err != nil {
+		return nil, err
 	}
-
-	commitHashRegex := regexp.MustCompile(`^[a-fA-F0-9]{7,40}$`)
-	if !commitHashRegex.MatchString(gitRef) {
-		errorEncoder(w, http.StatusBadRequest, "Invalid gitRef format: Expected a commit SHA")
-		return
+	opts := &github.CommitsListOptions{SHA: branch, ListOptions: github.ListOptions{PerPage: 1}}
+	commits, resp, err := client.Repositories.ListCommits(ctx, owner, repoName, opts)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: branch %s not found in repo %s", sources.ErrNotFound, branch, repo)
+		}
+		return nil, err
 	}
 
-	commits, statusCode, err := FetchCommits(repo, gitRef)
-	if err != nil {
-		errorEncoder(w, statusCode, err.Error())
-		return
+	if len(commits) > 0 {
+		return commits[0], nil
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	responseEncoder(w, statusCode, commits)
+	return nil, fmt.Errorf("no commits found")
 }
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Compare counts the commits between the current and latest references with the compare API
+func (s *Source) Compare(ctx context.Context, repo, current, latest string) (*sources.Comparison, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	client, err := s.NewGithubClient(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the counts are needed, so keep the commit list of the response short
+	cmp, resp, err := client.Repositories.CompareCommits(ctx, owner, repoName, current, latest, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: cannot compare %s with %s", sources.ErrNotFound, current, latest)
+		}
+		return nil, fmt.Errorf("error comparing references: %v", err)
+	}
+
+	// GitHub compares latest (head) against current (base), so its counts are seen from the latest side
+	return &sources.Comparison{
+		Status:   sources.ComparisonStatus(cmp.GetAheadBy(), cmp.GetBehindBy()),
+		BehindBy: cmp.GetAheadBy(),
+		AheadBy:  cmp.GetBehindBy(),
+		URL:      cmp.GetHTMLURL(),
+	}, nil
+}
+
+// ListCommitsBetween lists a page of the commits reachable from latest but not from current with the compare API
+func (s *Source) ListCommitsBetween(
+	ctx context.Context, repo, current, latest string, page sources.Page,
+) ([]*StandardizedEntity, bool, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	client, err := s.NewGithubClient(ctx, repo)
+	if err != nil {
+		return nil, false, err
+	}
+
+	opts := &github.ListOptions{Page: page.Number, PerPage: page.Size}
+	cmp, resp, err := client.Repositories.CompareCommits(ctx, owner, repoName, current, latest, opts)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, false, fmt.Errorf("%w: cannot compare %s with %s", sources.ErrNotFound, current, latest)
+		}
+		return nil, false, fmt.Errorf("error comparing references: %v", err)
+	}
+
+	commits := make([]*StandardizedEntity, 0, len(cmp.Commits))
+	for _, commit := range cmp.Commits {
+		commits = append(commits, StandardizeCommit(commit))
+	}
+	return commits, resp.NextPage != 0, nil
+}
@@ -3,144 +3,139 @@ package github
 import (
 	"context"
 	"crypto/rsa"
-	"encoding/json"
+	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
 )
 
-var (
-	appID          = os.Getenv("GITHUB_APP_ID")           // Your GitHub App ID
-	privateKeyPath = os.Getenv("GITHUB_PRIVATE_KEY_PATH") // Path to the private key file
-)
-
-// ErrorResponse represents an error message
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
-func errorEncoder(w http.ResponseWriter, status int, message string) {
-	w.WriteHeader(status)
-	err := json.NewEncoder(w).Encode(ErrorResponse{Error: message})
-	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-	}
-}
-
-func responseEncoder(w http.ResponseWriter, status int, body any) {
-	w.WriteHeader(status)
-	err := json.NewEncoder(w).Encode(body)
+// LoadPrivateKey loads the RSA private key from the file
+func LoadPrivateKey(filePath string) (*rsa.PrivateKey, error) {
+	privateKey, err := parsePrivateKey(filePath)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Printf("WARNING: %s. Falling back to unauthenticated mode.", err.Error())
+		return nil, nil // Continue without breaking
 	}
+	return privateKey, nil
 }
 
-// LoadPrivateKey loads the RSA private key from the file
-func LoadPrivateKey(filePath string) (*rsa.PrivateKey, error) {
+// parsePrivateKey reads and parses the RSA private key in the file
+func parsePrivateKey(filePath string) (*rsa.PrivateKey, error) {
 	keyData, err := os.ReadFile(filePath)
 	if err != nil {
-		errMsg := "WARNING: Private key not found or could not be read (%s). Falling back to unauthenticated mode."
-		log.Printf(errMsg, err.Error())
-		return nil, nil // Continue without breaking
+		return nil, fmt.Errorf("private key not found or could not be read (%v)", err)
 	}
 
 	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(keyData)
 	if err != nil {
-		log.Printf("WARNING: Failed to parse private key (%s). Falling back to unauthenticated mode.", err.Error())
-		return nil, nil // Continue without breaking
+		return nil, fmt.Errorf("failed to parse private key (%v)", err)
 	}
-
 	return privateKey, nil
 }
 
 // GenerateJWT generates a JWT for the GitHub App
-func GenerateJWT(privateKey *rsa.PrivateKey) (string, error) {
+func GenerateJWT(appID string, privateKey *rsa.PrivateKey) (string, error) {
 	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
 		"iat": now.Unix(),
-		"exp": now.Add(10 * time.Minute).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
 		"iss": appID,
 	})
 	return token.SignedString(privateKey)
 }
 
-// GetInstallationToken fetches an Installation Access Token
-func GetInstallationToken(jwtToken string, repo string) (string, error) {
-	client := github.NewClient(nil).WithAuthToken(jwtToken)
-	owner, repoName, _ := strings.Cut(repo, "/")
-	ctx := context.Background()
-	installation, _, err := client.Apps.FindRepositoryInstallation(ctx, owner, repoName)
-	if err != nil {
-		return "", err
-	}
-	token, _, err := client.Apps.CreateInstallationToken(ctx, *installation.ID, nil)
-	if err != nil {
-		return "", err
-	}
-	return *token.Token, nil
-}
-
-func GenerateAuthToken(repo string) string {
-	if privateKeyPath == "" {
-		return ""
-	}
-	privateKey, err := LoadPrivateKey(privateKeyPath)
-	if err != nil {
-		log.Println("WARNING: Failed to load private key. Falling back to unauthenticated mode.")
-		return ""
-	}
-	// Generate JWT for GitHub App
-	jwtToken, err := GenerateJWT(privateKey)
-	if err != nil {
-		log.Println("WARNING: Failed to generate JWT. Falling back to unauthenticated mode.")
-		return ""
-	}
-	// Get installation token using the JWT
-	accessToken, err := GetInstallationToken(jwtToken, repo)
+// GenerateAuthToken returns the token authenticating requests for repo, or "" for unauthenticated access
+func (s *Source) GenerateAuthToken(ctx context.Context, repo string) string {
+	accessToken, err := s.authToken(ctx, repo)
 	if err != nil {
 		log.Println(err)
-		log.Println("WARNING: Failed to get installation token. Falling back to unauthenticated mode.")
+		log.Printf("WARNING: Failed to get %s token. Falling back to unauthenticated mode.", s.auth.Mode())
 		return ""
 	}
 	return accessToken
 }
 
-func NewGithubClient(repo string) *github.Client {
-	authToken := GenerateAuthToken(repo)
+// newClient creates an unauthenticated client for the configured GitHub API
+func (s *Source) newClient() (*github.Client, error) {
 	client := github.NewClient(nil)
-	if authToken == "" {
-		log.Println("WARNING: Failed to get installation token. Returning unathenticated client.")
-		return client
+	if s.config.BaseURL == "" {
+		return client, nil
 	}
-	return client.WithAuthToken(authToken)
 
+	uploadURL := s.config.UploadURL
+	if uploadURL == "" {
+		uploadURL = s.config.BaseURL
+	}
+	return client.WithEnterpriseURLs(s.config.BaseURL, uploadURL)
 }
 
-// FetchLatestReference fetches the most recent reference (release or tag) by comparing dates
+// NewGithubClient creates a client authenticated for repo by the configured auth provider,
+// falling back to unauthenticated access when no token can be obtained unless auth is strict
+func (s *Source) NewGithubClient(ctx context.Context, repo string) (*github.Client, error) {
+	client, err := s.newClient()
+	if err != nil || s.auth.Mode() == AuthModeNone {
+		return client, err
+	}
+	authToken, err := s.authToken(ctx, repo)
+	if err != nil && s.config.StrictAuth {
+		return nil, fmt.Errorf("failed to authenticate in %s mode: %v", s.auth.Mode(), err)
+	}
+	if err != nil || authToken == "" {
+		log.Printf("WARNING: Failed to get auth token (%v). Returning unathenticated client.", err)
+		return client, nil
+	}
+	return client.WithAuthToken(authToken), nil
+}
+
+// FetchLatestReference fetches the most recent reference (release or tag) admitted by policy by comparing dates
 // Returns the latest as a StandardizedEntity, preferring releases over tags when dates are equal
-func FetchLatestReference(repo string) *StandardizedEntity {
+func (s *Source) FetchLatestReference(ctx context.Context, repo string, policy sources.LatestPolicy) *StandardizedEntity {
 	owner, repoName, _ := strings.Cut(repo, "/")
-	client := NewGithubClient(repo)
-	ctx := context.Background()
-
-	// Fetch latest release
-	var latestRelease *github.RepositoryRelease
-	releases, _, err := client.Repositories.ListReleases(ctx, owner, repoName, nil)
-	if err == nil && len(releases) > 0 {
-		latestRelease = releases[0]
+	client, err := s.NewGithubClient(ctx, repo)
+	if err != nil {
+		log.Printf("Error creating GitHub client: %v", err)
+		return nil
+	}
+
+	// Fetch latest release, skipping those the policy excludes
+	latestRelease, _, err := findPaged(
+		func(opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+			return client.Repositories.ListReleases(ctx, owner, repoName, opts)
+		},
+		func(release *github.RepositoryRelease) bool {
+			return policy.AdmitsRelease(release.GetTagName(), release.GetPrerelease(), release.GetDraft())
+		},
+	)
+	if err != nil {
+		latestRelease = nil
 	}
 
 	// Fetch latest tag
-	var latestTag *github.RepositoryTag
 	var latestTagCommit *github.RepositoryCommit
-	tags, _, err := client.Repositories.ListTags(ctx, owner, repoName, nil)
-	if err == nil && len(tags) > 0 {
-		latestTag = tags[0]
+	latestTag, _, err := findPaged(
+		func(opts *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
+			return client.Repositories.ListTags(ctx, owner, repoName, opts)
+		},
+		func(tag *github.RepositoryTag) bool { return policy.AdmitsTag(tag.GetName()) },
+	)
+	if err != nil {
+		latestTag = nil
+	}
+	var latestTagAnnotation *github.Tag
+	var latestTagKindKnown bool
+	if latestTag != nil {
+		// Annotated tags carry the date they were cut
+		_, latestTagAnnotation, _, err = lookupTag(ctx, client, owner, repoName, latestTag.GetName())
+		if err != nil {
+			log.Printf("Error looking up tag object of %s: %v", latestTag.GetName(), err)
+		}
+		latestTagKindKnown = err == nil
+
 		// Fetch commit for the tag to get date
 		if latestTag.Commit != nil && latestTag.Commit.SHA != nil {
 			commit, _, err := client.Repositories.GetCommit(ctx, owner, repoName, *latestTag.Commit.SHA, nil)
@@ -152,13 +147,16 @@ func FetchLatestReference(repo string) *StandardizedEntity {
 
 	// Compare dates and return the most recent
 	if latestRelease != nil && latestTagCommit != nil {
-		releaseTime := latestRelease.PublishedAt.Time
+		releaseTime := releaseDate(latestRelease).Time
 		tagTime := latestTagCommit.Commit.Author.Date.Time
+		if date := latestTagAnnotation.GetTagger().GetDate(); !date.IsZero() {
+			tagTime = date.Time
+		}
 
 		if releaseTime.After(tagTime) {
 			return StandardizeRelease(latestRelease)
 		}
-		return StandardizeTag(latestTag, latestTagCommit)
+		return standardizeLatestTag(latestTag, latestTagAnnotation, latestTagCommit, latestTagKindKnown)
 	}
 
 	// Return whichever is available
@@ -166,7 +164,7 @@ func FetchLatestReference(repo string) *StandardizedEntity {
 		return StandardizeRelease(latestRelease)
 	}
 	if latestTag != nil {
-		return StandardizeTag(latestTag, latestTagCommit)
+		return standardizeLatestTag(latestTag, latestTagAnnotation, latestTagCommit, latestTagKindKnown)
 	}
 
 	return nil
@@ -0,0 +1,294 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/sourcestest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSHA = "abc123def4567890abc123def4567890abc123de"
+
+// newTestGitHub starts an httptest stand-in of a GitHub Enterprise Server API.
+// handlers are keyed by the request path below the /api/v3 prefix.
+func newTestGitHub(t *testing.T, handlers map[string]http.HandlerFunc) *Source {
+	server := sourcestest.API{Prefix: "/api/v3", NotFound: `{"message":"Not Found"}`}.Serve(t, handlers)
+
+	source, err := NewSource(Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return source
+}
+
+// testCommitJSON returns a commit response for sha
+func testCommitJSON(sha, message, date string) string {
+	return fmt.Sprintf(`{
+		"sha": %q,
+		"html_url": "https://ghe.example.com/org/repo/commit/%s",
+		"author": {"login": "commitauthor"},
+		"commit": {"message": %q, "author": {"name": "Commit Author", "date": %q}}
+	}`, sha, sha, message, date)
+}
+
+func TestSourceEnterpriseBaseURL(t *testing.T) {
+	commit := testCommitJSON(testSHA, "Fix bug", "2025-06-15T10:30:00Z")
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/commits/" + testSHA: sourcestest.JSON(commit),
+		"/repos/org/repo/commits":            sourcestest.JSON("[" + commit + "]"),
+	})
+
+	output, err := sources.Resolve(context.Background(), source, sources.Query{
+		Repo:   "org/repo",
+		GitRef: testSHA,
+		Kind:   sources.KindCommit,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &StandardizedEntity{
+		Ref:         testSHA,
+		URL:         "https://ghe.example.com/org/repo/commit/" + testSHA,
+		Message:     "Fix bug",
+		Author:      "commitauthor",
+		PublishedAt: "2025-06-15 10:30:00 +0000 UTC",
+	}, output.Current)
+	assert.Equal(t, output.Current, output.Latest)
+}
+
+func TestSourceCommitNotFound(t *testing.T) {
+	source := newTestGitHub(t, map[string]http.HandlerFunc{})
+
+	_, err := source.ResolveCurrent(context.Background(), sources.Query{
+		Repo:   "org/repo",
+		GitRef: testSHA,
+		Kind:   sources.KindCommit,
+	})
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestFetchLatestReferenceSkipsPrereleases(t *testing.T) {
+	release := func(tag string, prerelease, draft bool) string {
+		// Drafts are not published yet
+		publishedAt := `"2025-06-16T12:00:00Z"`
+		if draft {
+			publishedAt = "null"
+		}
+		return fmt.Sprintf(`{
+			"tag_name": %q,
+			"html_url": "https://ghe.example.com/org/repo/releases/tag/%s",
+			"body": "Release notes",
+			"author": {"login": "releaseauthor"},
+			"created_at": "2025-06-17T12:00:00Z",
+			"published_at": %s,
+			"prerelease": %t,
+			"draft": %t
+		}`, tag, tag, publishedAt, prerelease, draft)
+	}
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/releases": sourcestest.JSON("[" + release("v3.0.0", false, true) + "," +
+			release("v2.0.0-rc1", true, false) + "," + release("v1.0.0", false, false) + "]"),
+		"/repos/org/repo/tags":               sourcestest.JSON(`[{"name": "v0.9.0", "commit": {"sha": "` + testSHA + `"}}]`),
+		"/repos/org/repo/commits/" + testSHA: sourcestest.JSON(testCommitJSON(testSHA, "Old", "2025-01-01T00:00:00Z")),
+	})
+
+	latest := source.FetchLatestReference(context.Background(), "org/repo", sources.LatestPolicy{})
+	if assert.NotNil(t, latest) {
+		assert.Equal(t, "v1.0.0", latest.Ref)
+	}
+
+	include := true
+	latest = source.FetchLatestReference(context.Background(), "org/repo", sources.LatestPolicy{
+		IncludePrereleases: &include,
+	})
+	if assert.NotNil(t, latest) {
+		assert.Equal(t, "v2.0.0-rc1", latest.Ref)
+		assert.True(t, latest.Prerelease)
+	}
+
+	latest = source.FetchLatestReference(context.Background(), "org/repo", sources.LatestPolicy{
+		IncludeDrafts: &include,
+	})
+	if assert.NotNil(t, latest) {
+		assert.Equal(t, "v3.0.0", latest.Ref)
+		assert.True(t, latest.Draft)
+		assert.Equal(t, "2025-06-17 12:00:00 +0000 UTC", latest.PublishedAt)
+	}
+
+	latest = source.FetchLatestReference(context.Background(), "org/repo", sources.LatestPolicy{
+		IncludeDrafts: &include,
+		ExcludeTags:   []sources.Pattern{sources.MustParsePattern(`^v3\.`)},
+	})
+	if assert.NotNil(t, latest) {
+		assert.Equal(t, "v1.0.0", latest.Ref)
+	}
+}
+
+func TestFetchLatestCommitOnBranch(t *testing.T) {
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/commits": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("sha") != "release" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = fmt.Fprint(w, `{"message": "No commit found for SHA: main"}`)
+				return
+			}
+			sourcestest.JSON("["+testCommitJSON(testSHA, "Release fix", "2025-06-15T10:30:00Z")+"]")(w, r)
+		},
+	})
+
+	commit, err := source.FetchLatestCommit(context.Background(), "org/repo", "release")
+	assert.NoError(t, err)
+	assert.Equal(t, testSHA, commit.GetSHA())
+
+	_, err = source.FetchLatestCommit(context.Background(), "org/repo", "main")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestSourceCompare(t *testing.T) {
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/compare/v1.0.0...v1.2.0": sourcestest.JSON(`{
+			"status": "ahead",
+			"ahead_by": 12,
+			"behind_by": 0,
+			"html_url": "https://github.com/org/repo/compare/v1.0.0...v1.2.0"
+		}`),
+		"/repos/org/repo/compare/hotfix...v1.2.0": sourcestest.JSON(`{"status": "diverged", "ahead_by": 12, "behind_by": 2}`),
+	})
+
+	comparison, err := source.Compare(context.Background(), "org/repo", "v1.0.0", "v1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, &sources.Comparison{
+		Status:   "behind",
+		BehindBy: 12,
+		URL:      "https://github.com/org/repo/compare/v1.0.0...v1.2.0",
+	}, comparison)
+
+	comparison, err = source.Compare(context.Background(), "org/repo", "hotfix", "v1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, &sources.Comparison{Status: "diverged", BehindBy: 12, AheadBy: 2}, comparison)
+
+	_, err = source.Compare(context.Background(), "org/repo", "v0.0.1", "v1.2.0")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestSourceExpandCommitSHA(t *testing.T) {
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/commits/abc1234": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, tagSHA)
+		},
+		"/repos/org/repo/commits/abcdef0": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = fmt.Fprint(w, `{"message": "The commit SHA abcdef0 is ambiguous"}`)
+		},
+		"/repos/org/repo/commits/0000000": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = fmt.Fprint(w, `{"message": "No commit found for SHA: 0000000"}`)
+		},
+	})
+
+	sha, err := source.ExpandCommitSHA(context.Background(), "org/repo", "abc1234")
+	assert.NoError(t, err)
+	assert.Equal(t, tagSHA, sha)
+
+	_, err = source.ExpandCommitSHA(context.Background(), "org/repo", "abcdef0")
+	assert.ErrorIs(t, err, sources.ErrAmbiguousRef)
+
+	_, err = source.ExpandCommitSHA(context.Background(), "org/repo", "0000000")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestSourceListCommitsBetween(t *testing.T) {
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/compare/v1.0.0...v1.2.0": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "2", r.URL.Query().Get("per_page"))
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=2&per_page=2>; rel="next"`, r.Host, r.URL.Path))
+				sourcestest.JSON(`{"commits": [`+
+					testCommitJSON("1111111111111111111111111111111111111111", "First", "2025-06-01T10:00:00Z")+","+
+					testCommitJSON("2222222222222222222222222222222222222222", "Second", "2025-06-02T10:00:00Z")+`]}`)(w, r)
+				return
+			}
+			// The author of the third commit has no GitHub account
+			sourcestest.JSON(`{"commits": [{
+				"sha": "3333333333333333333333333333333333333333",
+				"html_url": "https://ghe.example.com/org/repo/commit/3333333333333333333333333333333333333333",
+				"author": null,
+				"commit": {"message": "Third", "author": {"name": "External Author", "date": "2025-06-03T10:00:00Z"}}
+			}]}`)(w, r)
+		},
+	})
+
+	commits, more, err := source.ListCommitsBetween(context.Background(), "org/repo", "v1.0.0", "v1.2.0", sources.Page{Number: 1, Size: 2})
+	assert.NoError(t, err)
+	assert.True(t, more)
+	if assert.Len(t, commits, 2) {
+		assert.Equal(t, "First", commits[0].Message)
+		assert.Equal(t, "commitauthor", commits[1].Author)
+	}
+
+	commits, more, err = source.ListCommitsBetween(context.Background(), "org/repo", "v1.0.0", "v1.2.0", sources.Page{Number: 2, Size: 2})
+	assert.NoError(t, err)
+	assert.False(t, more)
+	if assert.Len(t, commits, 1) {
+		assert.Equal(t, "3333333333333333333333333333333333333333", commits[0].Ref)
+		assert.Equal(t, "External Author", commits[0].Author)
+	}
+}
+
+func TestSourcePullRequestsFor(t *testing.T) {
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/commits/v1.2.0": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, testSHA)
+		},
+		"/repos/org/repo/commits/" + testSHA + "/pulls": sourcestest.JSON(`[{
+			"number": 42,
+			"title": "Add feature",
+			"html_url": "https://github.com/org/repo/pull/42",
+			"user": {"login": "prauthor"},
+			"merged_at": "2025-06-15T10:30:00Z"
+		}]`),
+	})
+
+	pulls, err := source.PullRequestsFor(context.Background(), "org/repo", "v1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []*sources.PullRequest{{
+		Number:   42,
+		Title:    "Add feature",
+		URL:      "https://github.com/org/repo/pull/42",
+		Author:   "prauthor",
+		MergedAt: "2025-06-15 10:30:00 +0000 UTC",
+	}}, pulls)
+
+	pulls, err = source.PullRequestsFor(context.Background(), "org/repo", testSHA)
+	assert.NoError(t, err)
+	assert.Len(t, pulls, 1)
+}
+
+func TestSourceChecksFor(t *testing.T) {
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/commits/v1.2.0/check-runs": sourcestest.JSON(`{"total_count": 3, "check_runs": [
+			{"name": "build", "status": "completed", "conclusion": "success", "html_url": "https://github.com/org/repo/runs/1"},
+			{"name": "lint", "status": "completed", "conclusion": "skipped", "html_url": "https://github.com/org/repo/runs/2"},
+			{"name": "e2e", "status": "in_progress", "html_url": "https://github.com/org/repo/runs/3"}
+		]}`),
+		"/repos/org/repo/commits/v1.2.0/status": sourcestest.JSON(`{"state": "failure", "statuses": [
+			{"context": "ci/legacy", "state": "error", "target_url": "https://ci.example.com/builds/4"}
+		]}`),
+	})
+
+	checks, err := source.ChecksFor(context.Background(), "org/repo", "v1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []*sources.Check{
+		{Name: "build", State: sources.CheckSuccess, URL: "https://github.com/org/repo/runs/1"},
+		{Name: "lint", State: sources.CheckSuccess, URL: "https://github.com/org/repo/runs/2"},
+		{Name: "e2e", State: sources.CheckPending, URL: "https://github.com/org/repo/runs/3"},
+		{Name: "ci/legacy", State: sources.CheckFailure, URL: "https://ci.example.com/builds/4"},
+	}, checks)
+
+	_, err = source.ChecksFor(context.Background(), "org/repo", "v9.9.9")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
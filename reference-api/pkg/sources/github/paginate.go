@@ -0,0 +1,40 @@
+package github
+
+import "github.com/google/go-github/v67/github"
+
+// pageSize is the number of items requested per page of a listing, the maximum GitHub allows
+const pageSize = 100
+
+// listPaged collects the items of every page of a listing
+func listPaged[T any](list func(opts *github.ListOptions) ([]T, *github.Response, error)) ([]T, *github.Response, error) {
+	var all []T
+	_, resp, err := findPaged(list, func(item T) bool {
+		all = append(all, item)
+		return false
+	})
+	return all, resp, err
+}
+
+// findPaged walks the pages of a listing until match accepts an item, returning the zero value when none does.
+// The response of the last page requested is returned alongside any error.
+func findPaged[T any](
+	list func(opts *github.ListOptions) ([]T, *github.Response, error), match func(T) bool,
+) (T, *github.Response, error) {
+	var zero T
+	opts := &github.ListOptions{PerPage: pageSize}
+	for {
+		items, resp, err := list(opts)
+		if err != nil {
+			return zero, resp, err
+		}
+		for _, item := range items {
+			if match(item) {
+				return item, resp, nil
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return zero, resp, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
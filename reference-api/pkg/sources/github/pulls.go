@@ -0,0 +1,45 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// PullRequestsFor lists the pull requests associated with the commit that ref, a commit SHA, tag or release, points to
+func (s *Source) PullRequestsFor(ctx context.Context, repo, ref string) ([]*sources.PullRequest, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	client, err := s.NewGithubClient(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pull requests are associated with commits, so resolve tags to the commit they point to
+	sha := ref
+	if len(ref) != 40 || !sources.IsCommitSHA(ref) {
+		sha, _, err = client.Repositories.GetCommitSHA1(ctx, owner, repoName, ref, "")
+		if err != nil {
+			return nil, fmt.Errorf("error resolving commit of %s: %v", ref, err)
+		}
+	}
+
+	pulls, resp, err := listPaged(func(opts *github.ListOptions) ([]*github.PullRequest, *github.Response, error) {
+		return client.PullRequests.ListPullRequestsWithCommit(ctx, owner, repoName, sha, opts)
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: commit not found for gitRef: %s", sources.ErrNotFound, ref)
+		}
+		return nil, fmt.Errorf("error listing pull requests: %v", err)
+	}
+
+	standardized := make([]*sources.PullRequest, 0, len(pulls))
+	for _, pull := range pulls {
+		standardized = append(standardized, StandardizePullRequest(pull))
+	}
+	return standardized, nil
+}
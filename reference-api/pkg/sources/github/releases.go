@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
 )
 
 type Release struct {
@@ -24,73 +25,70 @@ type MergedReleases struct {
 	Current *Release `json:"current"`
 }
 
-func FetchReleases(repo, gitRef string) (*StandardizedOutput, error) {
+// FetchRelease fetches the release published for the gitRef tag
+func (s *Source) FetchRelease(ctx context.Context, repo, gitRef string) (*StandardizedEntity, error) {
 	owner, repoName, _ := strings.Cut(repo, "/")
-	client := NewGithubClient(repo)
-	ctx := context.Background()
-
-	releases, resp, err := client.Repositories.ListReleases(ctx, owner, repoName, nil)
+	client, err := s.NewGithubClient(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
 
+	release, resp, err := client.Repositories.GetReleaseByTag(ctx, owner, repoName, gitRef)
 	if err != nil {
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			return nil, fmt.Errorf("404 Not Found: No releases found for repo %s", repo)
+			return nil, fmt.Errorf("%w: no release found for gitRef %s in repo %s", sources.ErrNotFound, gitRef, repo)
 		}
-		return nil, fmt.Errorf("GitHub API error: %v", err)
+		log.Printf("Failed to get release %s of %s directly, searching all releases: %v", gitRef, repo, err)
+		return findRelease(ctx, client, repo, gitRef)
 	}
 
-	var matchingRelease *github.RepositoryRelease
+	return StandardizeRelease(release), nil
+}
 
-	// Find the release matching the gitRef
-	for _, release := range releases {
-		release := release
-		if *release.TagName == gitRef {
-			matchingRelease = release
-			break
+// findRelease searches every page of the releases of repo for the one published for the gitRef tag
+func findRelease(ctx context.Context, client *github.Client, repo, gitRef string) (*StandardizedEntity, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	matchingRelease, resp, err := findPaged(
+		func(opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+			return client.Repositories.ListReleases(ctx, owner, repoName, opts)
+		},
+		func(release *github.RepositoryRelease) bool { return release.GetTagName() == gitRef },
+	)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: no releases found for repo %s", sources.ErrNotFound, repo)
 		}
+		return nil, fmt.Errorf("GitHub API error: %v", err)
 	}
 
-	// Use unified latest that checks both releases and tags
-	latest := FetchLatestReference(repo)
+	if matchingRelease == nil {
+		return nil, fmt.Errorf("%w: no release found for gitRef %s in repo %s", sources.ErrNotFound, gitRef, repo)
+	}
 
-	return &StandardizedOutput{
-		Latest:  latest,
-		Current: StandardizeRelease(matchingRelease),
-	}, nil
+	return StandardizeRelease(matchingRelease), nil
 }
 
-// ReleasesHandler handles the API endpoint for fetching releases from target repositories
-func ReleasesHandler(w http.ResponseWriter, r *http.Request) {
-	repo := r.URL.Query().Get("repo")
-	gitRef := r.URL.Query().Get("gitRef")
-
-	// Validate query parameters
-	if repo == "" {
-		errorEncoder(w, http.StatusBadRequest, "Missing 'repo' query parameter")
-		return
-	}
-	if gitRef == "" {
-		errorEncoder(w, http.StatusBadRequest, "Missing 'gitRef' query parameter")
-		return
+// ListReleases lists every release of repo, most recently created first
+func (s *Source) ListReleases(ctx context.Context, repo string) ([]*StandardizedEntity, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	client, err := s.NewGithubClient(ctx, repo)
+	if err != nil {
+		return nil, err
 	}
 
-	// Fetch release information
-	releases, err := FetchReleases(repo, gitRef)
+	releases, resp, err := listPaged(func(opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+		return client.Repositories.ListReleases(ctx, owner, repoName, opts)
+	})
 	if err != nil {
-		log.Printf("Error fetching releases: %v", err)
-
-		// If the error is due to GitHub returning a non-200 response, set the correct status
-		if strings.Contains(err.Error(), "404") {
-			errorEncoder(w, http.StatusNotFound, "GitHub API returned 404: Release not found")
-		} else {
-			errorEncoder(w, http.StatusInternalServerError, "Failed to fetch release information")
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: no releases found for repo %s", sources.ErrNotFound, repo)
 		}
-		return
+		return nil, fmt.Errorf("GitHub API error: %v", err)
 	}
 
-	if releases == nil || (releases.Current == nil) {
-		errorEncoder(w, http.StatusNotFound, "No release found for the given repository and gitRef")
-		return
+	entities := make([]*StandardizedEntity, 0, len(releases))
+	for _, release := range releases {
+		entities = append(entities, StandardizeRelease(release))
 	}
-
-	responseEncoder(w, http.StatusOK, releases)
+	return entities, nil
 }
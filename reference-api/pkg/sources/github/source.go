@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Config configures a Source for github.com or a GitHub Enterprise Server instance
+type Config struct {
+	BaseURL        string // API base URL, e.g. "https://ghe.example.com/api/v3/". Empty for api.github.com
+	UploadURL      string // Upload API base URL. Defaults to BaseURL
+	AppID          string // GitHub App ID
+	PrivateKeyPath string // Path to the GitHub App private key file
+
+	AuthMode  AuthMode // How requests are authenticated. Inferred from the other fields when empty
+	Token     string   // Static token for AuthModeToken
+	TokenFile string   // File holding the static token for AuthModeToken, re-read when it changes
+
+	// StrictAuth refuses broken credentials instead of falling back to unauthenticated access
+	StrictAuth bool
+}
+
+// ConfigFromEnv reads the configuration of the default GitHub source from the environment
+func ConfigFromEnv() Config {
+	return Config{
+		BaseURL:        os.Getenv("GITHUB_API_URL"),
+		UploadURL:      os.Getenv("GITHUB_UPLOAD_URL"),
+		AppID:          os.Getenv("GITHUB_APP_ID"),
+		PrivateKeyPath: os.Getenv("GITHUB_PRIVATE_KEY_PATH"),
+		AuthMode:       AuthMode(os.Getenv("GITHUB_AUTH_MODE")),
+		Token:          os.Getenv("GITHUB_TOKEN"),
+		TokenFile:      os.Getenv("GITHUB_TOKEN_FILE"),
+		StrictAuth:     os.Getenv("GITHUB_AUTH_STRICT") == "true",
+	}
+}
+
+// Source resolves references against the GitHub REST API
+type Source struct {
+	config Config
+	auth   AuthProvider
+
+	mu      sync.Mutex
+	authErr error // Outcome of the last attempt to authenticate
+}
+
+// NewSource creates a Source for the GitHub instance described by config,
+// authenticating its requests as selected by the configured auth mode
+func NewSource(config Config) (*Source, error) {
+	s := &Source{config: config}
+	auth, err := newAuthProvider(config, s.newClient)
+	if err != nil {
+		return nil, err
+	}
+	s.auth = auth
+	return s, nil
+}
+
+// AuthMode returns the authentication mode the source is using
+func (s *Source) AuthMode() AuthMode {
+	return s.auth.Mode()
+}
+
+// ResolveCurrent resolves the gitRef as a GitHub release, tag or commit
+func (s *Source) ResolveCurrent(ctx context.Context, q sources.Query) (*StandardizedEntity, error) {
+	switch q.Kind {
+	case sources.KindRelease:
+		return s.FetchRelease(ctx, q.Repo, q.GitRef)
+	case sources.KindTag:
+		return s.FetchTag(ctx, q.Repo, q.GitRef)
+	case sources.KindCommit:
+		commit, err := s.FetchCommit(ctx, q.Repo, q.GitRef)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeCommit(commit), nil
+	}
+	return nil, fmt.Errorf("%w: unsupported reference kind %q", sources.ErrNotFound, q.Kind)
+}
+
+// ResolveLatest returns the head of the tracked branch for commits and the newest release or tag otherwise
+func (s *Source) ResolveLatest(ctx context.Context, q sources.Query) (*StandardizedEntity, error) {
+	if q.Kind == sources.KindCommit {
+		commit, err := s.FetchLatestCommit(ctx, q.Repo, q.Branch)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeCommit(commit), nil
+	}
+
+	// Use unified latest that checks both releases and tags
+	return s.FetchLatestReference(ctx, q.Repo, q.Latest), nil
+}
@@ -1,20 +1,17 @@
 package github
 
-import "github.com/google/go-github/v67/github"
+import (
+	"strings"
 
-// Create a standardized struct for Commits and Releases
-type StandardizedOutput struct {
-	Latest  *StandardizedEntity `json:"latest"`
-	Current *StandardizedEntity `json:"current"`
-}
+	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
 
-type StandardizedEntity struct {
-	Ref         string `json:"ref"`          // Commit SHA or Release Tag
-	URL         string `json:"url"`          // Commit URL or Release URL
-	Message     string `json:"message"`      // Commit Message or Release Body
-	Author      string `json:"author"`       // Commit Author Login or Release Author Login
-	PublishedAt string `json:"published_at"` // Commit Date or Release Published At
-}
+// StandardizedOutput and StandardizedEntity are shared by every source
+type (
+	StandardizedOutput = sources.StandardizedOutput
+	StandardizedEntity = sources.StandardizedEntity
+)
 
 // StandardizeCommit converts a RepositoryCommit into a StandardizedEntity
 func StandardizeCommit(commit *github.RepositoryCommit) *StandardizedEntity {
@@ -22,12 +19,45 @@ func StandardizeCommit(commit *github.RepositoryCommit) *StandardizedEntity {
 		return nil
 	}
 
-	return &StandardizedEntity{
-		Ref:         *commit.SHA,
-		URL:         *commit.HTMLURL,
-		Message:     *commit.Commit.Message,
-		Author:      *commit.Author.Login,
-		PublishedAt: commit.Commit.Author.Date.String(),
+	// Commits of authors without a GitHub account have no Author, only the name of their commit author
+	author := commit.GetAuthor().GetLogin()
+	if author == "" {
+		author = commit.GetCommit().GetAuthor().GetName()
+	}
+	entity := &StandardizedEntity{
+		Ref:          commit.GetSHA(),
+		URL:          commit.GetHTMLURL(),
+		Message:      commit.GetCommit().GetMessage(),
+		Author:       author,
+		Verification: commitVerification(commit),
+	}
+	if date := commit.GetCommit().GetAuthor().GetDate(); !date.IsZero() {
+		entity.PublishedAt = date.String()
+	}
+	return entity
+}
+
+// commitVerification returns the signature verification of a commit, signed by its committer
+func commitVerification(commit *github.RepositoryCommit) *sources.Verification {
+	signer := commit.GetCommitter().GetLogin()
+	if signer == "" {
+		signer = commit.GetCommit().GetCommitter().GetName()
+	}
+	return StandardizeVerification(commit.GetCommit().GetVerification(), signer)
+}
+
+// StandardizeVerification converts a SignatureVerification of an object signed by signer into a sources.Verification
+func StandardizeVerification(verification *github.SignatureVerification, signer string) *sources.Verification {
+	if verification == nil {
+		return nil
+	}
+	if verification.GetReason() == "unsigned" {
+		signer = ""
+	}
+	return &sources.Verification{
+		Verified: verification.GetVerified(),
+		Reason:   verification.GetReason(),
+		Signer:   signer,
 	}
 }
 
@@ -37,58 +67,142 @@ func StandardizeRelease(release *github.RepositoryRelease) *StandardizedEntity {
 		return nil
 	}
 
-	return &StandardizedEntity{
-		Ref:         *release.TagName,
-		URL:         *release.HTMLURL,
-		Message:     *release.Body,
-		Author:      *release.Author.Login,
-		PublishedAt: release.PublishedAt.String(),
+	entity := &StandardizedEntity{
+		Ref:        release.GetTagName(),
+		URL:        release.GetHTMLURL(),
+		Message:    release.GetBody(),
+		Author:     release.GetAuthor().GetLogin(),
+		Prerelease: release.GetPrerelease(),
+		Draft:      release.GetDraft(),
+	}
+	if date := releaseDate(release); !date.IsZero() {
+		entity.PublishedAt = date.String()
 	}
+	return entity
 }
 
-// StandardizeTag converts a Tag and its associated Commit into a StandardizedEntity
-func StandardizeTag(tag *github.RepositoryTag, commit *github.RepositoryCommit) *StandardizedEntity {
+// releaseDate returns when release was published or, for drafts which are not yet, when it was created
+func releaseDate(release *github.RepositoryRelease) github.Timestamp {
+	if date := release.GetPublishedAt(); !date.IsZero() {
+		return date
+	}
+	return release.GetCreatedAt()
+}
+
+// StandardizeTag converts a Tag, its associated Commit and, for annotated tags, its tag object into a StandardizedEntity.
+// Annotated tags use their own message, tagger, date and signature, lightweight tags, whose annotation is nil,
+// those of their commit.
+func StandardizeTag(tag *github.RepositoryTag, annotation *github.Tag, commit *github.RepositoryCommit) *StandardizedEntity {
 	if tag == nil {
 		return nil
 	}
 
+	// Fallback to basic tag info if commit details are unavailable
+	entity := &StandardizedEntity{
+		Ref:         *tag.Name,
+		URL:         "",
+		Message:     "",
+		Author:      "",
+		PublishedAt: "",
+		TagKind:     sources.TagLightweight,
+	}
+
 	// If we have the commit details, use them for richer information
 	if commit != nil && commit.Commit != nil {
-		author := ""
 		if commit.Author != nil && commit.Author.Login != nil {
-			author = *commit.Author.Login
+			entity.Author = *commit.Author.Login
 		}
-
-		message := ""
 		if commit.Commit.Message != nil {
-			message = *commit.Commit.Message
+			entity.Message = *commit.Commit.Message
 		}
-
-		publishedAt := ""
 		if commit.Commit.Author != nil && commit.Commit.Author.Date != nil {
-			publishedAt = commit.Commit.Author.Date.String()
+			entity.PublishedAt = commit.Commit.Author.Date.String()
 		}
-
-		url := ""
 		if commit.HTMLURL != nil {
-			url = *commit.HTMLURL
+			entity.URL = *commit.HTMLURL
 		}
+		entity.Verification = commitVerification(commit)
+	}
 
-		return &StandardizedEntity{
-			Ref:         *tag.Name,
-			URL:         url,
-			Message:     message,
-			Author:      author,
-			PublishedAt: publishedAt,
+	if annotation != nil {
+		tagger := annotation.GetTagger()
+		entity.TagKind = sources.TagAnnotated
+		if message := strings.TrimSpace(annotation.GetMessage()); message != "" {
+			entity.Message = message
+		}
+		if name := tagger.GetName(); name != "" {
+			entity.Author = name
+		}
+		if tagger.Date != nil {
+			entity.PublishedAt = tagger.Date.String()
 		}
+		entity.Verification = StandardizeVerification(annotation.GetVerification(), tagger.GetName())
 	}
 
-	// Fallback to basic tag info if commit details are unavailable
-	return &StandardizedEntity{
-		Ref:         *tag.Name,
-		URL:         "",
-		Message:     "",
-		Author:      "",
-		PublishedAt: "",
+	return entity
+}
+
+// StandardizePullRequest converts a PullRequest into a sources.PullRequest
+func StandardizePullRequest(pull *github.PullRequest) *sources.PullRequest {
+	if pull == nil {
+		return nil
+	}
+
+	mergedAt := ""
+	if pull.MergedAt != nil {
+		mergedAt = pull.MergedAt.String()
+	}
+
+	return &sources.PullRequest{
+		Number:   pull.GetNumber(),
+		Title:    pull.GetTitle(),
+		URL:      pull.GetHTMLURL(),
+		Author:   pull.GetUser().GetLogin(),
+		MergedAt: mergedAt,
+	}
+}
+
+// StandardizeCheckRun converts a CheckRun into a sources.Check.
+// Runs that concluded as neutral or skipped do not block anything and count as successful.
+func StandardizeCheckRun(run *github.CheckRun) *sources.Check {
+	if run == nil {
+		return nil
+	}
+
+	state := sources.CheckPending
+	if run.GetStatus() == "completed" {
+		switch run.GetConclusion() {
+		case "success", "neutral", "skipped":
+			state = sources.CheckSuccess
+		default:
+			state = sources.CheckFailure
+		}
+	}
+
+	return &sources.Check{
+		Name:  run.GetName(),
+		State: state,
+		URL:   run.GetHTMLURL(),
+	}
+}
+
+// StandardizeStatus converts a RepoStatus into a sources.Check
+func StandardizeStatus(status *github.RepoStatus) *sources.Check {
+	if status == nil {
+		return nil
+	}
+
+	state := sources.CheckPending
+	switch status.GetState() {
+	case "success":
+		state = sources.CheckSuccess
+	case "failure", "error":
+		state = sources.CheckFailure
+	}
+
+	return &sources.Check{
+		Name:  status.GetContext(),
+		State: state,
+		URL:   status.GetTargetURL(),
 	}
 }
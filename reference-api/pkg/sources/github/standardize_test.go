@@ -5,15 +5,17 @@ import (
 	"time"
 
 	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestStandardizeTag(t *testing.T) {
 	tests := []struct {
-		name   string
-		tag    *github.RepositoryTag
-		commit *github.RepositoryCommit
-		want   *StandardizedEntity
+		name       string
+		tag        *github.RepositoryTag
+		annotation *github.Tag
+		commit     *github.RepositoryCommit
+		want       *StandardizedEntity
 	}{
 		{
 			name: "Nil tag returns nil",
@@ -44,6 +46,89 @@ func TestStandardizeTag(t *testing.T) {
 				Message:     "Test commit message",
 				Author:      "testuser",
 				PublishedAt: "2025-01-01 12:00:00 +0000 UTC",
+				TagKind:     sources.TagLightweight,
+			},
+		},
+		{
+			name: "Annotated tag signed by its tagger",
+			tag: &github.RepositoryTag{
+				Name: github.String("v1.1.0"),
+			},
+			annotation: &github.Tag{
+				Tagger: &github.CommitAuthor{Name: github.String("Release Manager")},
+				Verification: &github.SignatureVerification{
+					Verified: github.Bool(true),
+					Reason:   github.String("valid"),
+				},
+			},
+			commit: &github.RepositoryCommit{
+				SHA: github.String("abc123"),
+				Commit: &github.Commit{
+					Message:      github.String("Test commit message"),
+					Verification: &github.SignatureVerification{Verified: github.Bool(false), Reason: github.String("unsigned")},
+				},
+			},
+			want: &StandardizedEntity{
+				Ref:          "v1.1.0",
+				Message:      "Test commit message",
+				Author:       "Release Manager",
+				TagKind:      sources.TagAnnotated,
+				Verification: &sources.Verification{Verified: true, Reason: "valid", Signer: "Release Manager"},
+			},
+		},
+		{
+			name: "Annotated tag described by its tagger, message and date",
+			tag: &github.RepositoryTag{
+				Name: github.String("v1.3.0"),
+			},
+			annotation: &github.Tag{
+				Message: github.String("Release 1.3.0\n"),
+				Tagger: &github.CommitAuthor{
+					Name: github.String("Release Manager"),
+					Date: &github.Timestamp{Time: time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC)},
+				},
+			},
+			commit: &github.RepositoryCommit{
+				SHA:     github.String("abc123"),
+				HTMLURL: github.String("https://github.com/test/repo/commit/abc123"),
+				Author:  &github.User{Login: github.String("testuser")},
+				Commit: &github.Commit{
+					Message: github.String("Test commit message"),
+					Author: &github.CommitAuthor{
+						Date: &github.Timestamp{Time: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+					},
+				},
+			},
+			want: &StandardizedEntity{
+				Ref:         "v1.3.0",
+				URL:         "https://github.com/test/repo/commit/abc123",
+				Message:     "Release 1.3.0",
+				Author:      "Release Manager",
+				PublishedAt: "2025-02-01 09:00:00 +0000 UTC",
+				TagKind:     sources.TagAnnotated,
+			},
+		},
+		{
+			name: "Lightweight tag signed by its commit",
+			tag: &github.RepositoryTag{
+				Name: github.String("v1.2.0"),
+			},
+			commit: &github.RepositoryCommit{
+				SHA:       github.String("abc123"),
+				Committer: &github.User{Login: github.String("web-flow")},
+				Commit: &github.Commit{
+					Message: github.String("Test commit message"),
+					Verification: &github.SignatureVerification{
+						Verified: github.Bool(false),
+						Reason:   github.String("unknown_key"),
+					},
+				},
+			},
+			want: &StandardizedEntity{
+				Ref:          "v1.2.0",
+				Message:      "Test commit message",
+				TagKind:      sources.TagLightweight,
+				Verification: &sources.Verification{Reason: "unknown_key", Signer: "web-flow"},
 			},
 		},
 		{
@@ -58,6 +143,7 @@ func TestStandardizeTag(t *testing.T) {
 				Message:     "",
 				Author:      "",
 				PublishedAt: "",
+				TagKind:     sources.TagLightweight,
 			},
 		},
 		{
@@ -77,13 +163,14 @@ func TestStandardizeTag(t *testing.T) {
 				Message:     "Partial commit",
 				Author:      "",
 				PublishedAt: "",
+				TagKind:     sources.TagLightweight,
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := StandardizeTag(tt.tag, tt.commit)
+			got := StandardizeTag(tt.tag, tt.annotation, tt.commit)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -123,6 +210,21 @@ func TestStandardizeCommit(t *testing.T) {
 				PublishedAt: "2025-06-15 10:30:00 +0000 UTC",
 			},
 		},
+		{
+			name: "Author without a GitHub account",
+			commit: &github.RepositoryCommit{
+				SHA: github.String("abc123def456"),
+				Commit: &github.Commit{
+					Message: github.String("Fix bug in handler"),
+					Author:  &github.CommitAuthor{Name: github.String("Commit Author")},
+				},
+			},
+			want: &StandardizedEntity{
+				Ref:     "abc123def456",
+				Message: "Fix bug in handler",
+				Author:  "Commit Author",
+			},
+		},
 	}
 
 	for _, tt := range tests {
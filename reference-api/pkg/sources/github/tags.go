@@ -8,57 +8,101 @@ import (
 	"strings"
 
 	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
 )
 
-// FetchTags fetches information about a specific git tag and the latest tag
-func FetchTags(repo, gitRef string) (*StandardizedOutput, error) {
+// FetchTag fetches information about a specific git tag
+func (s *Source) FetchTag(ctx context.Context, repo, gitRef string) (*StandardizedEntity, error) {
 	owner, repoName, _ := strings.Cut(repo, "/")
-	client := NewGithubClient(repo)
-	ctx := context.Background()
-
-	// List all tags
-	tags, resp, err := client.Repositories.ListTags(ctx, owner, repoName, nil)
+	client, err := s.NewGithubClient(ctx, repo)
 	if err != nil {
+		return nil, err
+	}
+
+	// Look the tag up by name rather than searching the tag listing
+	matchingTag, annotation, resp, lookupErr := lookupTag(ctx, client, owner, repoName, gitRef)
+	if err = lookupErr; err != nil {
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			return nil, fmt.Errorf("404 Not Found: No tags found for repo %s", repo)
+			return nil, fmt.Errorf("%w: tag %s not found in repo %s", sources.ErrNotFound, gitRef, repo)
+		}
+		log.Printf("Failed to get tag %s of %s directly, searching all tags: %v", gitRef, repo, err)
+		if matchingTag, err = findTag(ctx, client, repo, gitRef); err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("GitHub API error: %v", err)
 	}
 
-	if len(tags) == 0 {
-		return nil, fmt.Errorf("404 Not Found: No tags found for repo %s", repo)
+	// Fetch commit details for matching tag
+	matchingCommit, err := fetchCommitForTag(client, ctx, owner, repoName, matchingTag)
+	if err != nil {
+		log.Printf("Error: Failed to fetch commit for matching tag: %v", err)
+		return nil, fmt.Errorf("failed to fetch commit for tag %s: %v", gitRef, err)
 	}
 
-	var matchingTag *github.RepositoryTag
-
-	// Find the tag matching the gitRef
-	for _, tag := range tags {
-		tag := tag
-		if *tag.Name == gitRef {
-			matchingTag = tag
-			break
-		}
+	entity := StandardizeTag(matchingTag, annotation, matchingCommit)
+	if lookupErr != nil {
+		entity.TagKind = "" // The tag listing does not tell annotated tags apart
 	}
+	return entity, nil
+}
 
-	// If no matching tag found, return 404
-	if matchingTag == nil {
-		return nil, fmt.Errorf("404 Not Found: Tag %s not found in repo %s", gitRef, repo)
+// standardizeLatestTag standardizes the latest tag, leaving its kind unknown when its tag object could not be looked up
+func standardizeLatestTag(
+	tag *github.RepositoryTag, annotation *github.Tag, commit *github.RepositoryCommit, kindKnown bool,
+) *StandardizedEntity {
+	entity := StandardizeTag(tag, annotation, commit)
+	if !kindKnown {
+		entity.TagKind = ""
 	}
+	return entity
+}
 
-	// Fetch commit details for matching tag
-	matchingCommit, err := fetchCommitForTag(client, ctx, owner, repoName, matchingTag)
+// lookupTag resolves the gitRef tag through the git refs API, peeling annotated tags to their commit.
+// The tag object of an annotated tag is returned too, and nil for a lightweight tag.
+func lookupTag(
+	ctx context.Context, client *github.Client, owner, repo, gitRef string,
+) (*github.RepositoryTag, *github.Tag, *github.Response, error) {
+	ref, resp, err := client.Git.GetRef(ctx, owner, repo, "tags/"+gitRef)
 	if err != nil {
-		log.Printf("Error: Failed to fetch commit for matching tag: %v", err)
-		return nil, fmt.Errorf("failed to fetch commit for tag %s: %v", gitRef, err)
+		return nil, nil, resp, err
+	}
+
+	var annotation *github.Tag
+	object := ref.GetObject()
+	if object.GetType() == "tag" {
+		annotation, resp, err = client.Git.GetTag(ctx, owner, repo, object.GetSHA())
+		if err != nil {
+			return nil, nil, resp, err
+		}
+		object = annotation.GetObject()
 	}
 
-	// Use unified latest that checks both releases and tags
-	latest := FetchLatestReference(repo)
+	return &github.RepositoryTag{
+		Name:   github.String(gitRef),
+		Commit: &github.Commit{SHA: object.SHA},
+	}, annotation, resp, nil
+}
+
+// findTag searches every page of the tags of repo for the gitRef tag
+func findTag(ctx context.Context, client *github.Client, repo, gitRef string) (*github.RepositoryTag, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	matchingTag, resp, err := findPaged(
+		func(opts *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
+			return client.Repositories.ListTags(ctx, owner, repoName, opts)
+		},
+		func(tag *github.RepositoryTag) bool { return tag.GetName() == gitRef },
+	)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: no tags found for repo %s", sources.ErrNotFound, repo)
+		}
+		return nil, fmt.Errorf("GitHub API error: %v", err)
+	}
 
-	return &StandardizedOutput{
-		Latest:  latest,
-		Current: StandardizeTag(matchingTag, matchingCommit),
-	}, nil
+	// If no matching tag found, return 404
+	if matchingTag == nil {
+		return nil, fmt.Errorf("%w: tag %s not found in repo %s", sources.ErrNotFound, gitRef, repo)
+	}
+	return matchingTag, nil
 }
 
 // fetchCommitForTag fetches the commit that a tag points to
@@ -77,39 +121,27 @@ func fetchCommitForTag(
 	return commit, nil
 }
 
-// TagsHandler handles the API endpoint for fetching git tags from target repositories
-func TagsHandler(w http.ResponseWriter, r *http.Request) {
-	repo := r.URL.Query().Get("repo")
-	gitRef := r.URL.Query().Get("gitRef")
-
-	// Validate query parameters
-	if repo == "" {
-		errorEncoder(w, http.StatusBadRequest, "Missing 'repo' query parameter")
-		return
-	}
-	if gitRef == "" {
-		errorEncoder(w, http.StatusBadRequest, "Missing 'gitRef' query parameter")
-		return
+// ListTagNames returns the names of every tag of repo
+func (s *Source) ListTagNames(ctx context.Context, repo string) ([]string, error) {
+	owner, repoName, _ := strings.Cut(repo, "/")
+	client, err := s.NewGithubClient(ctx, repo)
+	if err != nil {
+		return nil, err
 	}
 
-	// Fetch tag information
-	tags, err := FetchTags(repo, gitRef)
+	tags, resp, err := listPaged(func(opts *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
+		return client.Repositories.ListTags(ctx, owner, repoName, opts)
+	})
 	if err != nil {
-		log.Printf("Error fetching tags: %v", err)
-
-		// If the error is due to GitHub returning a non-200 response, set the correct status
-		if strings.Contains(err.Error(), "404") {
-			errorEncoder(w, http.StatusNotFound, "Tag not found")
-		} else {
-			errorEncoder(w, http.StatusInternalServerError, "Failed to fetch tag information")
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: no tags found for repo %s", sources.ErrNotFound, repo)
 		}
-		return
+		return nil, fmt.Errorf("GitHub API error: %v", err)
 	}
 
-	if tags == nil || tags.Current == nil {
-		errorEncoder(w, http.StatusNotFound, "No tag found for the given repository and gitRef")
-		return
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.GetName())
 	}
-
-	responseEncoder(w, http.StatusOK, tags)
+	return names, nil
 }
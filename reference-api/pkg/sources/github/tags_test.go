@@ -0,0 +1,232 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/sourcestest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	tagSHA       = "fedcba9876543210fedcba9876543210fedcba98"
+	tagObjectSHA = "0123456789abcdef0123456789abcdef01234567"
+)
+
+// pagedResponse serves count items rendered by item, paginated with GitHub style Link headers.
+// requests counts the pages served.
+func pagedResponse(count int, item func(i int) string, requests *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		if perPage == 0 {
+			perPage = 30
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		start, end := (page-1)*perPage, min(page*perPage, count)
+		items := make([]string, 0, perPage)
+		for i := start; i < end; i++ {
+			items = append(items, item(i))
+		}
+		if end < count {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%d&per_page=%d>; rel="next"`,
+				r.Host, r.URL.Path, page+1, perPage))
+		}
+		sourcestest.JSON("["+strings.Join(items, ",")+"]")(w, r)
+	}
+}
+
+// failing is a handler standing in for an endpoint the server cannot serve
+func failing(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = fmt.Fprint(w, `{"message": "Server Error"}`)
+}
+
+// testTagName returns the name of the i-th newest of the many tags served in tests
+func testTagName(i int) string {
+	return fmt.Sprintf("v0.%d.0", 500-i)
+}
+
+func testReleaseJSON(tag string) string {
+	return fmt.Sprintf(`{
+		"tag_name": %q,
+		"html_url": "https://ghe.example.com/org/repo/releases/tag/%s",
+		"body": "Release notes",
+		"author": {"login": "releaseauthor"},
+		"published_at": "2025-06-16T12:00:00Z"
+	}`, tag, tag)
+}
+
+func TestFetchReleaseByTag(t *testing.T) {
+	var listed atomic.Int32
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/releases/tags/v0.250.0": sourcestest.JSON(testReleaseJSON("v0.250.0")),
+		"/repos/org/repo/releases":               pagedResponse(300, func(int) string { return "{}" }, &listed),
+	})
+
+	release, err := source.FetchRelease(context.Background(), "org/repo", "v0.250.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.250.0", release.Ref)
+
+	_, err = source.FetchRelease(context.Background(), "org/repo", "v9.9.9")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+	assert.Zero(t, listed.Load(), "releases should not be listed when looked up by tag")
+}
+
+func TestFetchReleaseFallsBackToPagination(t *testing.T) {
+	var listed atomic.Int32
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/releases/tags/": failing,
+		"/repos/org/repo/releases": pagedResponse(300, func(i int) string {
+			return testReleaseJSON(testTagName(i))
+		}, &listed),
+	})
+
+	release, err := source.FetchRelease(context.Background(), "org/repo", testTagName(250))
+	assert.NoError(t, err)
+	assert.Equal(t, testTagName(250), release.Ref)
+	assert.Equal(t, int32(3), listed.Load())
+
+	_, err = source.FetchRelease(context.Background(), "org/repo", "v9.9.9")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestListReleases(t *testing.T) {
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/releases": sourcestest.JSON(`[{
+			"tag_name": "v2.0.0",
+			"html_url": "https://ghe.example.com/org/repo/releases/tag/untagged-1",
+			"body": "Upcoming release",
+			"author": {"login": "releaseauthor"},
+			"created_at": "2025-06-17T12:00:00Z",
+			"published_at": null,
+			"draft": true
+		}, ` + testReleaseJSON("v1.0.0") + `]`),
+	})
+
+	releases, err := source.ListReleases(context.Background(), "org/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, []*StandardizedEntity{
+		{
+			Ref:         "v2.0.0",
+			URL:         "https://ghe.example.com/org/repo/releases/tag/untagged-1",
+			Message:     "Upcoming release",
+			Author:      "releaseauthor",
+			PublishedAt: "2025-06-17 12:00:00 +0000 UTC",
+			Draft:       true,
+		},
+		{
+			Ref:         "v1.0.0",
+			URL:         "https://ghe.example.com/org/repo/releases/tag/v1.0.0",
+			Message:     "Release notes",
+			Author:      "releaseauthor",
+			PublishedAt: "2025-06-16 12:00:00 +0000 UTC",
+		},
+	}, releases)
+}
+
+func TestFetchTagByRef(t *testing.T) {
+	var listed atomic.Int32
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/git/ref/tags/v1.2.3": sourcestest.JSON(`{
+			"ref": "refs/tags/v1.2.3", "object": {"type": "commit", "sha": "` + tagSHA + `"}
+		}`),
+		"/repos/org/repo/git/ref/tags/api/v2.0.0": sourcestest.JSON(`{
+			"ref": "refs/tags/api/v2.0.0", "object": {"type": "tag", "sha": "` + tagObjectSHA + `"}
+		}`),
+		"/repos/org/repo/git/tags/" + tagObjectSHA: sourcestest.JSON(`{
+			"tag": "api/v2.0.0", "sha": "` + tagObjectSHA + `", "object": {"type": "commit", "sha": "` + tagSHA + `"},
+			"message": "API 2.0.0\n", "tagger": {"name": "Release Manager", "date": "2025-07-02T09:00:00Z"}
+		}`),
+		"/repos/org/repo/commits/" + tagSHA: sourcestest.JSON(testCommitJSON(tagSHA, "Tagged change", "2025-07-01T08:00:00Z")),
+		"/repos/org/repo/tags":              pagedResponse(300, func(int) string { return "{}" }, &listed),
+	})
+
+	tests := []struct {
+		name   string
+		gitRef string
+		want   *StandardizedEntity
+	}{
+		{
+			name:   "Lightweight tag",
+			gitRef: "v1.2.3",
+			want: &StandardizedEntity{
+				Ref:         "v1.2.3",
+				URL:         "https://ghe.example.com/org/repo/commit/" + tagSHA,
+				Message:     "Tagged change",
+				Author:      "commitauthor",
+				PublishedAt: "2025-07-01 08:00:00 +0000 UTC",
+				TagKind:     sources.TagLightweight,
+			},
+		},
+		{
+			name:   "Annotated tag with a prefix",
+			gitRef: "api/v2.0.0",
+			want: &StandardizedEntity{
+				Ref:         "api/v2.0.0",
+				URL:         "https://ghe.example.com/org/repo/commit/" + tagSHA,
+				Message:     "API 2.0.0",
+				Author:      "Release Manager",
+				PublishedAt: "2025-07-02 09:00:00 +0000 UTC",
+				TagKind:     sources.TagAnnotated,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, err := source.FetchTag(context.Background(), "org/repo", tt.gitRef)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tag)
+		})
+	}
+
+	_, err := source.FetchTag(context.Background(), "org/repo", "v9.9.9")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+	assert.Zero(t, listed.Load(), "tags should not be listed when looked up by ref")
+}
+
+func TestFetchTagFallsBackToPagination(t *testing.T) {
+	var listed atomic.Int32
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/git/ref/": failing,
+		"/repos/org/repo/tags": pagedResponse(300, func(i int) string {
+			return fmt.Sprintf(`{"name": %q, "commit": {"sha": %q}}`, testTagName(i), tagSHA)
+		}, &listed),
+		"/repos/org/repo/commits/" + tagSHA: sourcestest.JSON(testCommitJSON(tagSHA, "Tagged change", "2025-07-01T08:00:00Z")),
+	})
+
+	tag, err := source.FetchTag(context.Background(), "org/repo", testTagName(299))
+	assert.NoError(t, err)
+	assert.Equal(t, testTagName(299), tag.Ref)
+	assert.Empty(t, tag.TagKind, "the tag listing does not tell annotated tags apart")
+	assert.Equal(t, int32(3), listed.Load())
+
+	_, err = source.FetchTag(context.Background(), "org/repo", "v9.9.9")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
+
+func TestListTagNames(t *testing.T) {
+	var listed atomic.Int32
+	source := newTestGitHub(t, map[string]http.HandlerFunc{
+		"/repos/org/repo/tags": pagedResponse(300, func(i int) string {
+			return fmt.Sprintf(`{"name": %q}`, testTagName(i))
+		}, &listed),
+	})
+
+	names, err := source.ListTagNames(context.Background(), "org/repo")
+	assert.NoError(t, err)
+	assert.Len(t, names, 300)
+	assert.Equal(t, testTagName(299), names[299])
+	assert.Equal(t, int32(3), listed.Load())
+}
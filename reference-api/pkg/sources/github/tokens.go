@@ -0,0 +1,219 @@
+package github
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+const (
+	// tokenRefreshMargin is how long before expiry a cached installation token is replaced
+	tokenRefreshMargin = 5 * time.Minute
+	// jwtLifetime is how long the JWTs used to authenticate as the GitHub App are valid for
+	jwtLifetime = 10 * time.Minute
+	// jwtRefreshMargin is how long before expiry a cached JWT is replaced
+	jwtRefreshMargin = time.Minute
+)
+
+// cachedToken is a token along with the time it stops being valid
+type cachedToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// usable reports whether the token can still be handed out at now, with margin to spare
+func (t cachedToken) usable(now time.Time, margin time.Duration) bool {
+	return t.token != "" && now.Add(margin).Before(t.expiresAt)
+}
+
+// keyedMutex holds a mutex per key, created on first use and removed once no caller holds or waits for it
+type keyedMutex[K comparable] struct {
+	mu    sync.Mutex
+	locks map[K]*refMutex
+}
+
+// refMutex is a mutex counting the callers holding or waiting for it
+type refMutex struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the mutex of key, returning the function unlocking it
+func (k *keyedMutex[K]) lock(key K) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[K]*refMutex)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &refMutex{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// TokenManager mints GitHub App installation tokens and caches them per installation until shortly
+// before they expire, along with the installation ID of each repository. It is safe for concurrent use.
+type TokenManager struct {
+	appID      string
+	privateKey *rsa.PrivateKey
+	newClient  func() (*github.Client, error)
+	now        func() time.Time
+
+	// lookups and mints serialize the lookup of each repository and the minting for each installation, so that
+	// concurrent callers share a single new token without waiting on other repositories and installations
+	lookups keyedMutex[string]
+	mints   keyedMutex[int64]
+
+	mu            sync.Mutex
+	jwt           cachedToken
+	installations map[string]int64 // "owner/repo" → installation ID
+	tokens        map[int64]cachedToken
+}
+
+// NewTokenManager creates a TokenManager for the GitHub App appID.
+// newClient returns an unauthenticated client for the GitHub API the App is installed on.
+func NewTokenManager(appID string, privateKey *rsa.PrivateKey, newClient func() (*github.Client, error)) *TokenManager {
+	return &TokenManager{
+		appID:         appID,
+		privateKey:    privateKey,
+		newClient:     newClient,
+		now:           time.Now,
+		installations: make(map[string]int64),
+		tokens:        make(map[int64]cachedToken),
+	}
+}
+
+// appClient returns a client authenticated as the GitHub App itself
+func (m *TokenManager) appClient() (*github.Client, error) {
+	m.mu.Lock()
+	jwt := m.jwt
+	m.mu.Unlock()
+
+	now := m.now()
+	if !jwt.usable(now, jwtRefreshMargin) {
+		token, err := GenerateJWT(m.appID, m.privateKey)
+		if err != nil {
+			return nil, &credentialError{fmt.Errorf("failed to generate JWT: %v", err)}
+		}
+		jwt = cachedToken{token: token, expiresAt: now.Add(jwtLifetime)}
+
+		m.mu.Lock()
+		m.jwt = jwt
+		m.mu.Unlock()
+	}
+
+	client, err := m.newClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.WithAuthToken(jwt.token), nil
+}
+
+// installationID returns the ID of the installation covering repo, looking it up on first use
+func (m *TokenManager) installationID(ctx context.Context, repo string) (int64, error) {
+	if id, ok := m.cachedInstallationID(repo); ok {
+		return id, nil
+	}
+
+	defer m.lookups.lock(repo)()
+	if id, ok := m.cachedInstallationID(repo); ok {
+		return id, nil
+	}
+
+	client, err := m.appClient()
+	if err != nil {
+		return 0, err
+	}
+
+	owner, repoName, _ := strings.Cut(repo, "/")
+	installation, resp, err := client.Apps.FindRepositoryInstallation(ctx, owner, repoName)
+	if err != nil {
+		return 0, appError(resp, err)
+	}
+
+	m.mu.Lock()
+	m.installations[repo] = installation.GetID()
+	m.mu.Unlock()
+	return installation.GetID(), nil
+}
+
+// cachedInstallationID returns the installation ID already known for repo
+func (m *TokenManager) cachedInstallationID(repo string) (int64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, ok := m.installations[repo]
+	return id, ok
+}
+
+// cachedToken returns the token for installation id if it is still usable
+func (m *TokenManager) cachedToken(id int64) (string, bool) {
+	m.mu.Lock()
+	cached := m.tokens[id]
+	m.mu.Unlock()
+	return cached.token, cached.usable(m.now(), tokenRefreshMargin)
+}
+
+// Token returns an installation access token for the installation covering repo
+func (m *TokenManager) Token(ctx context.Context, repo string) (string, error) {
+	id, err := m.installationID(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	if token, ok := m.cachedToken(id); ok {
+		return token, nil
+	}
+
+	defer m.mints.lock(id)()
+	if token, ok := m.cachedToken(id); ok {
+		return token, nil
+	}
+
+	client, err := m.appClient()
+	if err != nil {
+		return "", err
+	}
+
+	token, resp, err := client.Apps.CreateInstallationToken(ctx, id, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// The App was uninstalled or reinstalled, so look the installation up again next time
+			m.mu.Lock()
+			delete(m.installations, repo)
+			m.mu.Unlock()
+		}
+		return "", appError(resp, err)
+	}
+
+	m.mu.Lock()
+	m.tokens[id] = cachedToken{token: token.GetToken(), expiresAt: token.GetExpiresAt().Time}
+	m.mu.Unlock()
+	return token.GetToken(), nil
+}
+
+// appError marks err as a failure of the App credentials when GitHub refused to authenticate the App, as opposed to
+// a failure for the single repository or installation requested
+func appError(resp *github.Response, err error) error {
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		return &credentialError{err}
+	}
+	return err
+}
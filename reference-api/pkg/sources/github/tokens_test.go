@@ -0,0 +1,189 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/sourcestest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAppServer is an httptest stand-in of the GitHub App endpoints that counts the calls it receives
+type testAppServer struct {
+	lookups   atomic.Int32
+	mints     atomic.Int32
+	expiresIn time.Duration
+
+	// slow, when set, holds lookups of the "slow" owner until it is closed, once they signal started
+	slow    chan struct{}
+	started chan struct{}
+}
+
+func (a *testAppServer) start(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/{owner}/{repo}/installation", func(w http.ResponseWriter, r *http.Request) {
+		a.lookups.Add(1)
+		id := 1
+		switch r.PathValue("owner") {
+		case "other":
+			id = 2
+		case "uninstalled":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"message": "Not Found"}`)
+			return
+		case "slow":
+			a.started <- struct{}{}
+			<-a.slow
+		case "revoked":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = fmt.Fprint(w, `{"message": "A JSON web token could not be decoded"}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"id": %d}`, id)
+	})
+	mux.HandleFunc("/api/v3/app/installations/{id}/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		n := a.mints.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"token": "token-%s-%d", "expires_at": %q}`,
+			r.PathValue("id"), n, time.Now().Add(a.expiresIn).UTC().Format(time.RFC3339))
+	})
+	return sourcestest.NewServer(t, mux)
+}
+
+// newTestTokenManager creates a TokenManager talking to the stand-in at serverURL
+func newTestTokenManager(t *testing.T, serverURL string) *TokenManager {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return NewTokenManager("12345", privateKey, func() (*github.Client, error) {
+		return github.NewClient(nil).WithEnterpriseURLs(serverURL, serverURL)
+	})
+}
+
+func TestTokenManagerCachesTokens(t *testing.T) {
+	app := &testAppServer{expiresIn: time.Hour}
+	manager := newTestTokenManager(t, app.start(t).URL)
+	ctx := context.Background()
+
+	token, err := manager.Token(ctx, "org/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1-1", token)
+
+	// Repositories sharing an installation share its token
+	token, err = manager.Token(ctx, "org/other-repo")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1-1", token)
+
+	token, err = manager.Token(ctx, "other/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2-2", token)
+
+	token, err = manager.Token(ctx, "org/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1-1", token)
+
+	assert.Equal(t, int32(3), app.lookups.Load())
+	assert.Equal(t, int32(2), app.mints.Load())
+}
+
+func TestTokenManagerRefreshesBeforeExpiry(t *testing.T) {
+	app := &testAppServer{expiresIn: time.Hour}
+	manager := newTestTokenManager(t, app.start(t).URL)
+	ctx := context.Background()
+
+	now := time.Now()
+	manager.now = func() time.Time { return now }
+
+	token, err := manager.Token(ctx, "org/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1-1", token)
+
+	now = now.Add(time.Hour - tokenRefreshMargin - time.Minute)
+	token, err = manager.Token(ctx, "org/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1-1", token)
+
+	now = now.Add(2 * time.Minute)
+	token, err = manager.Token(ctx, "org/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1-2", token)
+
+	assert.Equal(t, int32(1), app.lookups.Load())
+}
+
+func TestTokenManagerConcurrentUse(t *testing.T) {
+	app := &testAppServer{expiresIn: time.Hour}
+	manager := newTestTokenManager(t, app.start(t).URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := manager.Token(context.Background(), "org/repo")
+			assert.NoError(t, err)
+			assert.NotEmpty(t, token)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), app.lookups.Load())
+	assert.Equal(t, int32(1), app.mints.Load())
+
+	// The locks of repositories and installations are released once no caller holds them
+	assert.Empty(t, manager.lookups.locks)
+	assert.Empty(t, manager.mints.locks)
+}
+
+func TestTokenManagerDoesNotBlockOtherRepositories(t *testing.T) {
+	app := &testAppServer{expiresIn: time.Hour, slow: make(chan struct{}), started: make(chan struct{})}
+	manager := newTestTokenManager(t, app.start(t).URL)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := manager.Token(context.Background(), "slow/repo")
+		assert.NoError(t, err)
+	}()
+	<-app.started
+
+	// Repositories of other installations are served while the slow lookup is pending
+	token, err := manager.Token(context.Background(), "other/repo")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	close(app.slow)
+	<-done
+}
+
+func TestSourceStatusIgnoresRepositoryFailures(t *testing.T) {
+	app := &testAppServer{expiresIn: time.Hour}
+	source := &Source{auth: newTestTokenManager(t, app.start(t).URL)}
+	ctx := context.Background()
+
+	_, err := source.authToken(ctx, "org/repo")
+	assert.NoError(t, err)
+	assert.True(t, source.Status().Healthy)
+
+	// The App not being installed on a repository says nothing of its credentials
+	_, err = source.authToken(ctx, "uninstalled/repo")
+	assert.Error(t, err)
+	assert.True(t, source.Status().Healthy)
+
+	_, err = source.authToken(ctx, "revoked/repo")
+	assert.Error(t, err)
+	assert.False(t, source.Status().Healthy)
+
+	_, err = source.authToken(ctx, "org/repo")
+	assert.NoError(t, err)
+	assert.True(t, source.Status().Healthy)
+}
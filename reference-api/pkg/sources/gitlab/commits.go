@@ -0,0 +1,43 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Commit is the subset of a GitLab commit used by reference-api
+type Commit struct {
+	ID           string     `json:"id"`
+	Message      string     `json:"message"`
+	AuthorName   string     `json:"author_name"`
+	AuthoredDate *time.Time `json:"authored_date"`
+	WebURL       string     `json:"web_url"`
+}
+
+// FetchCommit fetches a specific commit by its SHA
+func (c *Client) FetchCommit(ctx context.Context, repo, gitRef string) (*Commit, error) {
+	var commit Commit
+	if err := c.get(ctx, projectPath(repo)+"/repository/commits/"+url.PathEscape(gitRef), nil, &commit); err != nil {
+		return nil, err
+	}
+	return &commit, nil
+}
+
+// FetchLatestCommit fetches the head commit of branch, or of the default branch when branch is empty
+func (c *Client) FetchLatestCommit(ctx context.Context, repo, branch string) (*Commit, error) {
+	query := url.Values{"per_page": {"1"}}
+	if branch != "" {
+		query.Set("ref_name", branch)
+	}
+
+	var commits []*Commit
+	if err := c.get(ctx, projectPath(repo)+"/repository/commits", query, &commits); err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits found")
+	}
+	return commits[0], nil
+}
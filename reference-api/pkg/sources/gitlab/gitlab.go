@@ -0,0 +1,112 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Client is a minimal GitLab REST API v4 client
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a Client for the GitLab instance at baseURL, e.g. "https://gitlab.example.com".
+// An empty token results in unauthenticated requests, which only work for public projects.
+// A nil httpClient uses http.DefaultClient.
+func NewClient(baseURL, token string, httpClient *http.Client) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/") + "/api/v4",
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// pageSize is the number of items requested per page of a listing, the maximum GitLab allows
+const pageSize = 100
+
+// projectPath returns the API path of a project addressed by its full path, e.g. "group/subgroup/project"
+func projectPath(repo string) string {
+	return "/projects/" + url.PathEscape(repo)
+}
+
+// get decodes the JSON response of a GET request into out.
+// A 404 from GitLab is returned as an error wrapping sources.ErrNotFound.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	if err := sources.GetJSON(ctx, c.httpClient, endpoint, header, out); err != nil {
+		return fmt.Errorf("GitLab %w", err)
+	}
+	return nil
+}
+
+// findPaged walks the pages of the listing at path until match accepts an item, returning the zero value when none does
+func findPaged[T any](ctx context.Context, c *Client, path string, query url.Values, match func(T) bool) (T, error) {
+	var zero T
+	query.Set("per_page", strconv.Itoa(pageSize))
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var items []T
+		if err := c.get(ctx, path, query, &items); err != nil {
+			return zero, err
+		}
+		for _, item := range items {
+			if match(item) {
+				return item, nil
+			}
+		}
+		if len(items) < pageSize {
+			return zero, nil
+		}
+	}
+}
+
+// FetchLatestReference fetches the most recent reference (release or tag) admitted by policy by comparing dates
+// Returns the latest as a StandardizedEntity, preferring releases over tags when dates are equal
+func (c *Client) FetchLatestReference(
+	ctx context.Context, repo string, policy sources.LatestPolicy,
+) (*StandardizedEntity, error) {
+	latestRelease, err := c.FetchLatestRelease(ctx, repo, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	latestTag, err := c.FetchLatestTag(ctx, repo, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare dates and return the most recent
+	if latestRelease != nil && latestTag != nil {
+		if latestTag.date().After(latestRelease.date()) {
+			return StandardizeTag(latestTag), nil
+		}
+		return StandardizeRelease(latestRelease), nil
+	}
+
+	// Return whichever is available
+	if latestRelease != nil {
+		return StandardizeRelease(latestRelease), nil
+	}
+	if latestTag != nil {
+		return StandardizeTag(latestTag), nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,317 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources/sourcestest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testToken   = "test-token"
+	projectBase = "/api/v4/projects/group%2Fsubgroup%2Fproject"
+)
+
+// testAPI is a stand-in of the GitLab API, matching escaped paths as project paths are escaped
+var testAPI = sourcestest.API{
+	Header:        "PRIVATE-TOKEN",
+	Authorization: testToken,
+	NotFound:      `{"message":"404 Not Found"}`,
+	EscapedPaths:  true,
+}
+
+const (
+	testCommit = `{
+		"id": "abc123def4567890abc123def4567890abc123de",
+		"message": "Fix bug in handler",
+		"author_name": "Commit Author",
+		"authored_date": "2025-06-15T10:30:00Z",
+		"web_url": "https://gitlab.example.com/group/subgroup/project/-/commit/abc123de"
+	}`
+	testHeadCommit = `{
+		"id": "fedcba9876543210fedcba9876543210fedcba98",
+		"message": "Latest change",
+		"author_name": "Head Author",
+		"authored_date": "2025-07-01T08:00:00Z",
+		"web_url": "https://gitlab.example.com/group/subgroup/project/-/commit/fedcba98"
+	}`
+	testRelease = `{
+		"tag_name": "v1.0.0",
+		"description": "## What's New\n- Feature A",
+		"released_at": "2025-06-16T12:00:00Z",
+		"author": {"username": "releaseauthor"},
+		"_links": {"self": "https://gitlab.example.com/group/subgroup/project/-/releases/v1.0.0"}
+	}`
+	testTag = `{
+		"name": "v1.1.0",
+		"message": "",
+		"target": "fedcba9876543210fedcba9876543210fedcba98",
+		"commit": ` + testHeadCommit + `
+	}`
+)
+
+func TestSourceResolveCurrent(t *testing.T) {
+	server := testAPI.ServeJSON(t, map[string]string{
+		projectBase + "/releases/v1.0.0":                                             testRelease,
+		projectBase + "/repository/tags/v1.1.0":                                      testTag,
+		projectBase + "/repository/commits/abc123def4567890abc123def4567890abc123de": testCommit,
+	})
+	source := NewSource(server.URL, testToken, nil)
+
+	tests := []struct {
+		name    string
+		kind    sources.Kind
+		gitRef  string
+		want    *StandardizedEntity
+		wantErr error
+	}{
+		{
+			name:   "Release",
+			kind:   sources.KindRelease,
+			gitRef: "v1.0.0",
+			want: &StandardizedEntity{
+				Ref:         "v1.0.0",
+				URL:         "https://gitlab.example.com/group/subgroup/project/-/releases/v1.0.0",
+				Message:     "## What's New\n- Feature A",
+				Author:      "releaseauthor",
+				PublishedAt: "2025-06-16 12:00:00 +0000 UTC",
+			},
+		},
+		{
+			name:   "Tag uses the commit it points to",
+			kind:   sources.KindTag,
+			gitRef: "v1.1.0",
+			want: &StandardizedEntity{
+				Ref:         "v1.1.0",
+				URL:         "https://gitlab.example.com/group/subgroup/project/-/commit/fedcba98",
+				Message:     "Latest change",
+				Author:      "Head Author",
+				PublishedAt: "2025-07-01 08:00:00 +0000 UTC",
+				TagKind:     sources.TagLightweight,
+			},
+		},
+		{
+			name:   "Commit",
+			kind:   sources.KindCommit,
+			gitRef: "abc123def4567890abc123def4567890abc123de",
+			want: &StandardizedEntity{
+				Ref:         "abc123def4567890abc123def4567890abc123de",
+				URL:         "https://gitlab.example.com/group/subgroup/project/-/commit/abc123de",
+				Message:     "Fix bug in handler",
+				Author:      "Commit Author",
+				PublishedAt: "2025-06-15 10:30:00 +0000 UTC",
+			},
+		},
+		{
+			name:    "Missing release",
+			kind:    sources.KindRelease,
+			gitRef:  "v1.1.0",
+			wantErr: sources.ErrNotFound,
+		},
+		{
+			name:    "Invalid commit SHA",
+			kind:    sources.KindCommit,
+			gitRef:  "not-a-sha",
+			wantErr: sources.ErrInvalidRef,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := source.ResolveCurrent(context.Background(), sources.Query{
+				Repo:   "group/subgroup/project",
+				GitRef: tt.gitRef,
+				Kind:   tt.kind,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSourceResolveLatest(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		kind      sources.Kind
+		wantRef   string
+	}{
+		{
+			name: "Tag newer than release",
+			responses: map[string]string{
+				projectBase + "/releases":        "[" + testRelease + "]",
+				projectBase + "/repository/tags": "[" + testTag + "]",
+			},
+			kind:    sources.KindRelease,
+			wantRef: "v1.1.0",
+		},
+		{
+			name: "Only a release",
+			responses: map[string]string{
+				projectBase + "/releases":        "[" + testRelease + "]",
+				projectBase + "/repository/tags": "[]",
+			},
+			kind:    sources.KindTag,
+			wantRef: "v1.0.0",
+		},
+		{
+			name: "Upcoming releases are skipped",
+			responses: map[string]string{
+				projectBase + "/releases": `[
+					{"tag_name": "v2.0.0", "upcoming_release": true},
+					` + testRelease + `
+				]`,
+				projectBase + "/repository/tags": "[]",
+			},
+			kind:    sources.KindRelease,
+			wantRef: "v1.0.0",
+		},
+		{
+			name: "Prereleases are skipped",
+			responses: map[string]string{
+				projectBase + "/releases": `[
+					{"tag_name": "v2.0.0-rc1", "released_at": "2025-07-10T12:00:00Z"},
+					` + testRelease + `
+				]`,
+				projectBase + "/repository/tags": "[]",
+			},
+			kind:    sources.KindRelease,
+			wantRef: "v1.0.0",
+		},
+		{
+			name: "Head of the default branch for commits",
+			responses: map[string]string{
+				projectBase + "/repository/commits": "[" + testHeadCommit + "]",
+			},
+			kind:    sources.KindCommit,
+			wantRef: "fedcba9876543210fedcba9876543210fedcba98",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testAPI.ServeJSON(t, tt.responses)
+			source := NewSource(server.URL, testToken, nil)
+
+			got, err := source.ResolveLatest(context.Background(), sources.Query{
+				Repo: "group/subgroup/project",
+				Kind: tt.kind,
+			})
+			assert.NoError(t, err)
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tt.wantRef, got.Ref)
+			}
+		})
+	}
+}
+
+func TestClientListTagNames(t *testing.T) {
+	server := sourcestest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, projectBase+"/repository/tags", r.URL.EscapedPath())
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		// 150 tags, served 100 per page
+		tags := make([]string, 0, pageSize)
+		for i := (page - 1) * pageSize; i < min(page*pageSize, 150); i++ {
+			tags = append(tags, fmt.Sprintf(`{"name": "v1.%d.0"}`, i))
+		}
+		_, _ = fmt.Fprint(w, "["+strings.Join(tags, ",")+"]")
+	}))
+
+	names, err := NewClient(server.URL, "", nil).ListTagNames(context.Background(), "group/subgroup/project")
+	assert.NoError(t, err)
+	assert.Len(t, names, 150)
+	assert.Equal(t, "v1.149.0", names[149])
+}
+
+func TestSourceListReleases(t *testing.T) {
+	server := testAPI.ServeJSON(t, map[string]string{
+		projectBase + "/releases": `[
+			{"tag_name": "v2.0.0", "description": "Upcoming", "upcoming_release": true},
+			` + testRelease + `
+		]`,
+	})
+
+	releases, err := NewSource(server.URL, testToken, nil).ListReleases(context.Background(), "group/subgroup/project")
+	assert.NoError(t, err)
+	if assert.Len(t, releases, 2) {
+		assert.True(t, releases[0].Draft)
+		assert.Equal(t, "v1.0.0", releases[1].Ref)
+		assert.Equal(t, "## What's New\n- Feature A", releases[1].Message)
+	}
+}
+
+func TestSourcePullRequestsFor(t *testing.T) {
+	server := testAPI.ServeJSON(t, map[string]string{
+		projectBase + "/repository/commits/v1.1.0/merge_requests": `[{
+			"iid": 7,
+			"title": "Add feature",
+			"web_url": "https://gitlab.example.com/group/subgroup/project/-/merge_requests/7",
+			"author": {"username": "mrauthor"},
+			"merged_at": "2025-06-15T10:30:00Z"
+		}, {
+			"iid": 8,
+			"title": "Still open",
+			"web_url": "https://gitlab.example.com/group/subgroup/project/-/merge_requests/8"
+		}]`,
+	})
+
+	pulls, err := NewSource(server.URL, testToken, nil).PullRequestsFor(context.Background(), "group/subgroup/project", "v1.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []*sources.PullRequest{
+		{
+			Number:   7,
+			Title:    "Add feature",
+			URL:      "https://gitlab.example.com/group/subgroup/project/-/merge_requests/7",
+			Author:   "mrauthor",
+			MergedAt: "2025-06-15 10:30:00 +0000 UTC",
+		},
+		{
+			Number: 8,
+			Title:  "Still open",
+			URL:    "https://gitlab.example.com/group/subgroup/project/-/merge_requests/8",
+		},
+	}, pulls)
+}
+
+func TestSourceChecksFor(t *testing.T) {
+	server := testAPI.ServeJSON(t, map[string]string{
+		projectBase + "/repository/commits/v1.1.0/statuses": `[
+			{"name": "build", "status": "success", "target_url": "https://gitlab.example.com/jobs/1"},
+			{"name": "test", "status": "failed", "target_url": "https://gitlab.example.com/jobs/2"},
+			{"name": "deploy", "status": "manual"}
+		]`,
+	})
+
+	checks, err := NewSource(server.URL, testToken, nil).ChecksFor(context.Background(), "group/subgroup/project", "v1.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []*sources.Check{
+		{Name: "build", State: sources.CheckSuccess, URL: "https://gitlab.example.com/jobs/1"},
+		{Name: "test", State: sources.CheckFailure, URL: "https://gitlab.example.com/jobs/2"},
+		{Name: "deploy", State: sources.CheckPending},
+	}, checks)
+}
+
+func TestSourceExpandCommitSHA(t *testing.T) {
+	server := testAPI.ServeJSON(t, map[string]string{
+		projectBase + "/repository/commits/abc123d": testCommit,
+	})
+	source := NewSource(server.URL, testToken, nil)
+
+	sha, err := source.ExpandCommitSHA(context.Background(), "group/subgroup/project", "abc123d")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123def4567890abc123def4567890abc123de", sha)
+
+	_, err = source.ExpandCommitSHA(context.Background(), "group/subgroup/project", "0000000")
+	assert.ErrorIs(t, err, sources.ErrNotFound)
+}
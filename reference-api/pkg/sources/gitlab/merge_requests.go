@@ -0,0 +1,41 @@
+package gitlab
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// MergeRequest is the subset of a GitLab merge request used by reference-api
+type MergeRequest struct {
+	IID      int        `json:"iid"`
+	Title    string     `json:"title"`
+	WebURL   string     `json:"web_url"`
+	Author   *User      `json:"author"`
+	MergedAt *time.Time `json:"merged_at"`
+}
+
+// FetchMergeRequests fetches the merge requests that introduced the commit gitRef points to
+func (c *Client) FetchMergeRequests(ctx context.Context, repo, gitRef string) ([]*MergeRequest, error) {
+	var mergeRequests []*MergeRequest
+	path := projectPath(repo) + "/repository/commits/" + url.PathEscape(gitRef) + "/merge_requests"
+	if err := c.get(ctx, path, nil, &mergeRequests); err != nil {
+		return nil, err
+	}
+	return mergeRequests, nil
+}
+
+// PullRequestsFor lists the merge requests that introduced the commit ref points to
+func (s *Source) PullRequestsFor(ctx context.Context, repo, ref string) ([]*sources.PullRequest, error) {
+	mergeRequests, err := s.client.FetchMergeRequests(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	pulls := make([]*sources.PullRequest, 0, len(mergeRequests))
+	for _, mergeRequest := range mergeRequests {
+		pulls = append(pulls, StandardizeMergeRequest(mergeRequest))
+	}
+	return pulls, nil
+}
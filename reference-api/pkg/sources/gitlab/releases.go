@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Release is the subset of a GitLab release used by reference-api
+type Release struct {
+	TagName     string     `json:"tag_name"`
+	Description string     `json:"description"`
+	CreatedAt   *time.Time `json:"created_at"`
+	ReleasedAt  *time.Time `json:"released_at"`
+	Upcoming    bool       `json:"upcoming_release"` // Scheduled for a future release date
+	Author      *User      `json:"author"`
+	Links       struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+// User is the subset of a GitLab user used by reference-api
+type User struct {
+	Username string `json:"username"`
+}
+
+// date returns when the release was published, falling back to its creation date
+func (r *Release) date() time.Time {
+	if r.ReleasedAt != nil {
+		return *r.ReleasedAt
+	}
+	if r.CreatedAt != nil {
+		return *r.CreatedAt
+	}
+	return time.Time{}
+}
+
+// FetchRelease fetches the release published for the gitRef tag
+func (c *Client) FetchRelease(ctx context.Context, repo, gitRef string) (*Release, error) {
+	var release Release
+	if err := c.get(ctx, projectPath(repo)+"/releases/"+url.PathEscape(gitRef), nil, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// FetchLatestRelease fetches the most recently released release admitted by policy, or nil if the project has none.
+// GitLab has no prerelease flag, so prereleases are told apart by the pre-release identifiers of their semantic version
+// tag, and upcoming releases count as drafts.
+func (c *Client) FetchLatestRelease(ctx context.Context, repo string, policy sources.LatestPolicy) (*Release, error) {
+	query := url.Values{
+		"order_by": {"released_at"},
+		"sort":     {"desc"},
+	}
+	return findPaged(ctx, c, projectPath(repo)+"/releases", query, func(release *Release) bool {
+		return policy.AdmitsRelease(release.TagName, policy.IsPrereleaseTag(release.TagName), release.Upcoming)
+	})
+}
+
+// ListReleases lists every release of repo, most recently released first
+func (c *Client) ListReleases(ctx context.Context, repo string) ([]*Release, error) {
+	query := url.Values{
+		"order_by": {"released_at"},
+		"sort":     {"desc"},
+	}
+
+	var releases []*Release
+	_, err := findPaged(ctx, c, projectPath(repo)+"/releases", query, func(release *Release) bool {
+		releases = append(releases, release)
+		return false
+	})
+	return releases, err
+}
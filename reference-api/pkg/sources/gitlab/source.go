@@ -0,0 +1,88 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Source resolves references against the GitLab REST API
+type Source struct {
+	client *Client
+}
+
+// NewSource creates a Source for the GitLab instance at baseURL
+func NewSource(baseURL, token string, httpClient *http.Client) *Source {
+	return &Source{client: NewClient(baseURL, token, httpClient)}
+}
+
+// ResolveCurrent resolves the gitRef as a GitLab release, tag or commit
+func (s *Source) ResolveCurrent(ctx context.Context, q sources.Query) (*StandardizedEntity, error) {
+	switch q.Kind {
+	case sources.KindRelease:
+		release, err := s.client.FetchRelease(ctx, q.Repo, q.GitRef)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeRelease(release), nil
+	case sources.KindTag:
+		tag, err := s.client.FetchTag(ctx, q.Repo, q.GitRef)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeTag(tag), nil
+	case sources.KindCommit:
+		if !sources.IsCommitSHA(q.GitRef) {
+			return nil, fmt.Errorf("%w format: Expected a commit SHA", sources.ErrInvalidRef)
+		}
+		commit, err := s.client.FetchCommit(ctx, q.Repo, q.GitRef)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeCommit(commit), nil
+	}
+	return nil, fmt.Errorf("%w: unsupported reference kind %q", sources.ErrNotFound, q.Kind)
+}
+
+// ResolveLatest returns the head of the tracked branch for commits and the newest release or tag otherwise
+func (s *Source) ResolveLatest(ctx context.Context, q sources.Query) (*StandardizedEntity, error) {
+	if q.Kind == sources.KindCommit {
+		commit, err := s.client.FetchLatestCommit(ctx, q.Repo, q.Branch)
+		if err != nil {
+			return nil, err
+		}
+		return StandardizeCommit(commit), nil
+	}
+
+	return s.client.FetchLatestReference(ctx, q.Repo, q.Latest)
+}
+
+// ExpandCommitSHA returns the full SHA of the commit whose SHA starts with prefix.
+// GitLab does not list the commits an ambiguous prefix matches, and reports such prefixes as not found.
+func (s *Source) ExpandCommitSHA(ctx context.Context, repo, prefix string) (string, error) {
+	commit, err := s.client.FetchCommit(ctx, repo, prefix)
+	if err != nil {
+		return "", err
+	}
+	return commit.ID, nil
+}
+
+// ListTagNames returns the names of every tag of repo, newest first
+func (s *Source) ListTagNames(ctx context.Context, repo string) ([]string, error) {
+	return s.client.ListTagNames(ctx, repo)
+}
+
+// ListReleases returns every release of repo, newest first
+func (s *Source) ListReleases(ctx context.Context, repo string) ([]*StandardizedEntity, error) {
+	releases, err := s.client.ListReleases(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*StandardizedEntity, 0, len(releases))
+	for _, release := range releases {
+		entities = append(entities, StandardizeRelease(release))
+	}
+	return entities, nil
+}
@@ -0,0 +1,125 @@
+package gitlab
+
+import (
+	"strings"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// StandardizedEntity is shared by every source
+type StandardizedEntity = sources.StandardizedEntity
+
+// StandardizeCommit converts a Commit into a StandardizedEntity
+func StandardizeCommit(commit *Commit) *StandardizedEntity {
+	if commit == nil {
+		return nil
+	}
+
+	publishedAt := ""
+	if commit.AuthoredDate != nil {
+		publishedAt = commit.AuthoredDate.String()
+	}
+
+	return &StandardizedEntity{
+		Ref:         commit.ID,
+		URL:         commit.WebURL,
+		Message:     commit.Message,
+		Author:      commit.AuthorName,
+		PublishedAt: publishedAt,
+	}
+}
+
+// StandardizeRelease converts a Release into a StandardizedEntity
+func StandardizeRelease(release *Release) *StandardizedEntity {
+	if release == nil {
+		return nil
+	}
+
+	author := ""
+	if release.Author != nil {
+		author = release.Author.Username
+	}
+
+	publishedAt := ""
+	if date := release.date(); !date.IsZero() {
+		publishedAt = date.String()
+	}
+
+	return &StandardizedEntity{
+		Ref:         release.TagName,
+		URL:         release.Links.Self,
+		Message:     release.Description,
+		Author:      author,
+		PublishedAt: publishedAt,
+		Draft:       release.Upcoming,
+	}
+}
+
+// StandardizeTag converts a Tag and the commit embedded in it into a StandardizedEntity
+func StandardizeTag(tag *Tag) *StandardizedEntity {
+	if tag == nil {
+		return nil
+	}
+
+	// Use the commit details for richer information, as the GitHub source does
+	entity := StandardizeCommit(tag.Commit)
+	if entity == nil {
+		entity = &StandardizedEntity{}
+	}
+	entity.Ref = tag.Name
+	entity.TagKind = sources.TagLightweight
+
+	// Only annotated tags carry a message of their own
+	if tag.Message != "" {
+		entity.Message = strings.TrimSpace(tag.Message)
+		entity.TagKind = sources.TagAnnotated
+	}
+	return entity
+}
+
+// StandardizeMergeRequest converts a MergeRequest into a sources.PullRequest
+func StandardizeMergeRequest(mergeRequest *MergeRequest) *sources.PullRequest {
+	if mergeRequest == nil {
+		return nil
+	}
+
+	author := ""
+	if mergeRequest.Author != nil {
+		author = mergeRequest.Author.Username
+	}
+
+	mergedAt := ""
+	if mergeRequest.MergedAt != nil {
+		mergedAt = mergeRequest.MergedAt.String()
+	}
+
+	return &sources.PullRequest{
+		Number:   mergeRequest.IID,
+		Title:    mergeRequest.Title,
+		URL:      mergeRequest.WebURL,
+		Author:   author,
+		MergedAt: mergedAt,
+	}
+}
+
+// StandardizeStatus converts a CommitStatus into a sources.Check.
+// Skipped jobs do not block anything and count as successful, and canceled ones as failed.
+func StandardizeStatus(status *CommitStatus) *sources.Check {
+	if status == nil {
+		return nil
+	}
+
+	state := sources.CheckPending
+	switch status.Status {
+	case "success", "skipped":
+		state = sources.CheckSuccess
+	case "failed", "canceled":
+		state = sources.CheckFailure
+	}
+
+	return &sources.Check{
+		Name:  status.Name,
+		State: state,
+		URL:   status.TargetURL,
+	}
+}
@@ -0,0 +1,39 @@
+package gitlab
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// CommitStatus is the subset of a GitLab commit status used by reference-api
+type CommitStatus struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	TargetURL string `json:"target_url"`
+}
+
+// FetchStatuses fetches every status reported for the commit gitRef points to
+func (c *Client) FetchStatuses(ctx context.Context, repo, gitRef string) ([]*CommitStatus, error) {
+	var statuses []*CommitStatus
+	path := projectPath(repo) + "/repository/commits/" + url.PathEscape(gitRef) + "/statuses"
+	_, err := findPaged(ctx, c, path, url.Values{}, func(status *CommitStatus) bool {
+		statuses = append(statuses, status)
+		return false
+	})
+	return statuses, err
+}
+
+// ChecksFor lists the pipeline jobs and external statuses reported for the commit ref points to
+func (s *Source) ChecksFor(ctx context.Context, repo, ref string) ([]*sources.Check, error) {
+	statuses, err := s.client.FetchStatuses(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	checks := make([]*sources.Check, 0, len(statuses))
+	for _, status := range statuses {
+		checks = append(checks, StandardizeStatus(status))
+	}
+	return checks, nil
+}
@@ -0,0 +1,60 @@
+package gitlab
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// Tag is the subset of a GitLab repository tag used by reference-api
+type Tag struct {
+	Name    string  `json:"name"`
+	Message string  `json:"message"`
+	Target  string  `json:"target"`
+	Commit  *Commit `json:"commit"`
+}
+
+// date returns the date of the commit the tag points to
+func (t *Tag) date() time.Time {
+	if t.Commit != nil && t.Commit.AuthoredDate != nil {
+		return *t.Commit.AuthoredDate
+	}
+	return time.Time{}
+}
+
+// FetchTag fetches a specific repository tag by name
+func (c *Client) FetchTag(ctx context.Context, repo, gitRef string) (*Tag, error) {
+	var tag Tag
+	if err := c.get(ctx, projectPath(repo)+"/repository/tags/"+url.PathEscape(gitRef), nil, &tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FetchLatestTag fetches the most recently updated tag admitted by policy, or nil if the project has none
+func (c *Client) FetchLatestTag(ctx context.Context, repo string, policy sources.LatestPolicy) (*Tag, error) {
+	query := url.Values{
+		"order_by": {"updated"},
+		"sort":     {"desc"},
+	}
+	return findPaged(ctx, c, projectPath(repo)+"/repository/tags", query, func(tag *Tag) bool {
+		return policy.AdmitsTag(tag.Name)
+	})
+}
+
+// ListTagNames returns the names of every tag of repo, most recently updated first
+func (c *Client) ListTagNames(ctx context.Context, repo string) ([]string, error) {
+	query := url.Values{
+		"order_by": {"updated"},
+		"sort":     {"desc"},
+	}
+
+	var names []string
+	_, err := findPaged(ctx, c, projectPath(repo)+"/repository/tags", query, func(tag *Tag) bool {
+		names = append(names, tag.Name)
+		return false
+	})
+	return names, err
+}
@@ -0,0 +1,39 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// GetJSON performs a GET request against a forge API and decodes the JSON response into out.
+// A 404 response is returned as an error wrapping ErrNotFound.
+func GetJSON(ctx context.Context, client *http.Client, endpoint string, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("API error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: API returned 404 for %s", ErrNotFound, req.URL.Path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error: %s returned %s", req.URL.Path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,195 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// LatestStrategy selects how the latest release or tag of a repository is chosen
+type LatestStrategy string
+
+const (
+	// LatestByDate picks the most recently published release or tag
+	LatestByDate LatestStrategy = "date"
+	// LatestByVersion picks the tag with the highest semantic version
+	LatestByVersion LatestStrategy = "version"
+)
+
+// defaultTagPrefixes are removed from tag names before parsing them as versions when none are configured
+var defaultTagPrefixes = []string{"v"}
+
+// LatestPolicy controls which reference is reported as the latest one
+type LatestPolicy struct {
+	Strategy    LatestStrategy `json:"strategy"`    // Defaults to LatestByDate
+	TagPrefixes []string       `json:"tagPrefixes"` // Removed from tag names before parsing them as versions
+
+	IncludePrereleases *bool     `json:"includePrereleases"` // Whether prereleases count as latest. Defaults to false
+	IncludeDrafts      *bool     `json:"includeDrafts"`      // Whether draft releases count as latest. Defaults to false
+	ExcludeTags        []Pattern `json:"excludeTags"`        // Regular expressions matching tag names that never count as latest
+
+	scope TagScope // Release stream the latest reference is chosen from, set from Query.Scope by ResolveLatest
+}
+
+// Validate reports whether the policy can be applied
+func (p LatestPolicy) Validate() error {
+	switch p.Strategy {
+	case "", LatestByDate, LatestByVersion:
+	default:
+		return fmt.Errorf("unknown latest strategy %q", p.Strategy)
+	}
+
+	return nil
+}
+
+// Override returns the policy with the fields set in override replacing its own
+func (p LatestPolicy) Override(override LatestPolicy) LatestPolicy {
+	if override.Strategy != "" {
+		p.Strategy = override.Strategy
+	}
+	if override.TagPrefixes != nil {
+		p.TagPrefixes = override.TagPrefixes
+	}
+	if override.IncludePrereleases != nil {
+		p.IncludePrereleases = override.IncludePrereleases
+	}
+	if override.IncludeDrafts != nil {
+		p.IncludeDrafts = override.IncludeDrafts
+	}
+	if override.ExcludeTags != nil {
+		p.ExcludeTags = override.ExcludeTags
+	}
+	return p
+}
+
+// AdmitsRelease reports whether the release of the tag tagName, flagged by its source as a prerelease or a draft,
+// may be chosen as the latest reference
+func (p LatestPolicy) AdmitsRelease(tagName string, prerelease, draft bool) bool {
+	if draft && (p.IncludeDrafts == nil || !*p.IncludeDrafts) {
+		return false
+	}
+	return p.admits(tagName, prerelease)
+}
+
+// IsPrereleaseTag reports whether the tag name is a semantic version with pre-release identifiers, such as
+// "v2.0.0-rc1", for sources that don't flag prereleases
+func (p LatestPolicy) IsPrereleaseTag(name string) bool {
+	version, ok := p.parseVersion(name)
+	return ok && version.Prerelease != ""
+}
+
+// AdmitsTag reports whether the tag name may be chosen as the latest reference
+func (p LatestPolicy) AdmitsTag(name string) bool {
+	return p.admits(name, false)
+}
+
+// FirstAdmittedTag returns the first of names that may be chosen as the latest reference, or "" if none may
+func (p LatestPolicy) FirstAdmittedTag(names []string) string {
+	for _, name := range names {
+		if p.AdmitsTag(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// admits applies the scope, prerelease and excluded tag settings to the reference named name
+func (p LatestPolicy) admits(name string, prerelease bool) bool {
+	if !p.scope.Matches(name) {
+		return false
+	}
+	if prerelease && (p.IncludePrereleases == nil || !*p.IncludePrereleases) {
+		return false
+	}
+	for _, pattern := range p.ExcludeTags {
+		if pattern.MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseVersion parses the tag name as a semantic version once the prefix of the release stream is removed
+func (p LatestPolicy) parseVersion(name string) (Version, bool) {
+	return ParseVersion(strings.TrimPrefix(name, p.scope.Prefix), p.tagPrefixes())
+}
+
+// tagPrefixes returns the configured tag prefixes, or the default ones when none are configured
+func (p LatestPolicy) tagPrefixes() []string {
+	if p.TagPrefixes == nil {
+		return defaultTagPrefixes
+	}
+	return p.TagPrefixes
+}
+
+// TagLister is implemented by sources that can list the names of the tags of a repository,
+// which choosing the latest reference by version relies on
+type TagLister interface {
+	// ListTagNames returns the names of every tag of repo
+	ListTagNames(ctx context.Context, repo string) ([]string, error)
+}
+
+// ResolveLatest returns the latest reference for q within q.Scope, applying the strategy of q.Latest.
+// Commits always track the head of a branch, and sources that cannot list tags always pick latest by date.
+// Sources apply the scope, prerelease, draft and excluded tag settings of q.Latest themselves when picking latest by date.
+func ResolveLatest(ctx context.Context, src Source, q Query) (*StandardizedEntity, error) {
+	q.Latest.scope = q.Scope
+	lister, ok := src.(TagLister)
+	if q.Kind == KindCommit || q.Latest.Strategy != LatestByVersion || !ok {
+		return src.ResolveLatest(ctx, q)
+	}
+
+	names, err := lister.ListTagNames(ctx, q.Repo)
+	if err != nil {
+		return nil, err
+	}
+	candidates := byVersion(names, q.Latest)
+	if len(candidates) == 0 {
+		log.Printf("No admitted tag of %s is a semantic version, choosing latest by date", q.Repo)
+		return src.ResolveLatest(ctx, q)
+	}
+
+	for _, name := range candidates {
+		// Prefer the release published for the tag, whose notes describe it best
+		release, err := src.ResolveCurrent(ctx, Query{Repo: q.Repo, GitRef: name, Kind: KindRelease})
+		if err == nil {
+			if q.Latest.AdmitsRelease(name, release.Prerelease, release.Draft) {
+				return release, nil
+			}
+			continue // The tag was released as a prerelease or draft
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return src.ResolveCurrent(ctx, Query{Repo: q.Repo, GitRef: name, Kind: KindTag})
+	}
+	return nil, fmt.Errorf("%w: every versioned tag of %s was released as a prerelease or draft", ErrNotFound, q.Repo)
+}
+
+// byVersion returns the names admitted by the policy that are semantic versions, highest version first.
+// Versions with a pre-release part count as prereleases. Of names with the same version, the first one comes first.
+func byVersion(names []string, policy LatestPolicy) []string {
+	type candidate struct {
+		name    string
+		version Version
+	}
+	var candidates []candidate
+	for _, name := range names {
+		version, ok := policy.parseVersion(name)
+		if ok && policy.admits(name, version.Prerelease != "") {
+			candidates = append(candidates, candidate{name: name, version: version})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].version.Compare(candidates[j].version) > 0
+	})
+
+	sorted := make([]string, len(candidates))
+	for i, c := range candidates {
+		sorted[i] = c.name
+	}
+	return sorted
+}
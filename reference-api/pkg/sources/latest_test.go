@@ -0,0 +1,164 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// taggedSource is a Source with the given tags, of which only those in releases have been released.
+// Releases in prereleases are flagged as prereleases.
+type taggedSource struct {
+	tags        []string
+	releases    map[string]bool
+	prereleases map[string]bool
+}
+
+func (s *taggedSource) ResolveCurrent(ctx context.Context, q Query) (*StandardizedEntity, error) {
+	if q.Kind == KindRelease && !s.releases[q.GitRef] {
+		return nil, fmt.Errorf("%w: release %s", ErrNotFound, q.GitRef)
+	}
+	entity := &StandardizedEntity{Ref: q.GitRef, Message: string(q.Kind)}
+	if q.Kind == KindRelease && s.prereleases[q.GitRef] {
+		entity.Prerelease = true
+	}
+	return entity, nil
+}
+
+func (s *taggedSource) ResolveLatest(ctx context.Context, q Query) (*StandardizedEntity, error) {
+	return &StandardizedEntity{Ref: s.tags[0], Message: "newest by date"}, nil
+}
+
+func (s *taggedSource) ListTagNames(ctx context.Context, repo string) ([]string, error) {
+	return s.tags, nil
+}
+
+func TestResolveLatest(t *testing.T) {
+	source := &taggedSource{
+		// Newest first, with a backported patch cut after v2.0.0
+		tags:     []string{"v1.4.9", "app/v9.0.0", "v2.0.0", "v2.0.0-rc.1", "v1.4.8", "nightly"},
+		releases: map[string]bool{"v2.0.0": true},
+	}
+
+	include := true
+	tests := []struct {
+		name   string
+		query  Query
+		source Source
+		want   *StandardizedEntity
+	}{
+		{
+			name:   "By date by default",
+			query:  Query{Kind: KindTag},
+			source: source,
+			want:   &StandardizedEntity{Ref: "v1.4.9", Message: "newest by date"},
+		},
+		{
+			name:   "By version prefers the release",
+			query:  Query{Kind: KindTag, Latest: LatestPolicy{Strategy: LatestByVersion}},
+			source: source,
+			want:   &StandardizedEntity{Ref: "v2.0.0", Message: "release"},
+		},
+		{
+			name:   "By version with a prefix falls back to the tag",
+			query:  Query{Kind: KindTag, Latest: LatestPolicy{Strategy: LatestByVersion, TagPrefixes: []string{"app/v"}}},
+			source: source,
+			want:   &StandardizedEntity{Ref: "app/v9.0.0", Message: "tag"},
+		},
+		{
+			name:   "By version skips pre-release versions",
+			query:  Query{Kind: KindTag, Latest: LatestPolicy{Strategy: LatestByVersion}},
+			source: &taggedSource{tags: []string{"v3.0.0-rc.1", "v2.0.0"}},
+			want:   &StandardizedEntity{Ref: "v2.0.0", Message: "tag"},
+		},
+		{
+			name:   "By version includes pre-release versions when configured",
+			query:  Query{Kind: KindTag, Latest: LatestPolicy{Strategy: LatestByVersion, IncludePrereleases: &include}},
+			source: &taggedSource{tags: []string{"v3.0.0-rc.1", "v2.0.0"}},
+			want:   &StandardizedEntity{Ref: "v3.0.0-rc.1", Message: "tag"},
+		},
+		{
+			name:  "By version skips tags released as prereleases",
+			query: Query{Kind: KindTag, Latest: LatestPolicy{Strategy: LatestByVersion}},
+			source: &taggedSource{
+				tags:        []string{"v3.0.0", "v2.0.0"},
+				releases:    map[string]bool{"v3.0.0": true, "v2.0.0": true},
+				prereleases: map[string]bool{"v3.0.0": true},
+			},
+			want: &StandardizedEntity{Ref: "v2.0.0", Message: "release"},
+		},
+		{
+			name:   "By version skips excluded tags",
+			query:  Query{Kind: KindTag, Latest: LatestPolicy{Strategy: LatestByVersion, ExcludeTags: []Pattern{MustParsePattern(`^v2\.`)}}},
+			source: source,
+			want:   &StandardizedEntity{Ref: "v1.4.9", Message: "tag"},
+		},
+		{
+			name:   "By date without semantic versions",
+			query:  Query{Kind: KindTag, Latest: LatestPolicy{Strategy: LatestByVersion}},
+			source: &taggedSource{tags: []string{"nightly", "stable"}},
+			want:   &StandardizedEntity{Ref: "nightly", Message: "newest by date"},
+		},
+		{
+			name:   "Commits track the branch",
+			query:  Query{Kind: KindCommit, Latest: LatestPolicy{Strategy: LatestByVersion}},
+			source: source,
+			want:   &StandardizedEntity{Ref: "v1.4.9", Message: "newest by date"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveLatest(context.Background(), tt.source, tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLatestPolicyOverride(t *testing.T) {
+	global := LatestPolicy{Strategy: LatestByVersion, TagPrefixes: []string{"v"}}
+
+	assert.Equal(t, global, global.Override(LatestPolicy{}))
+	assert.Equal(t,
+		LatestPolicy{Strategy: LatestByDate, TagPrefixes: []string{"v"}},
+		global.Override(LatestPolicy{Strategy: LatestByDate}))
+	assert.Equal(t,
+		LatestPolicy{Strategy: LatestByVersion, TagPrefixes: []string{"api/v"}},
+		global.Override(LatestPolicy{TagPrefixes: []string{"api/v"}}))
+
+	include := true
+	assert.Equal(t,
+		LatestPolicy{Strategy: LatestByVersion, TagPrefixes: []string{"v"}, IncludePrereleases: &include},
+		global.Override(LatestPolicy{IncludePrereleases: &include}))
+
+	assert.NoError(t, global.Validate())
+	assert.EqualError(t, LatestPolicy{Strategy: "alphabetical"}.Validate(), `unknown latest strategy "alphabetical"`)
+}
+
+func TestLatestPolicyAdmits(t *testing.T) {
+	include := true
+	policy := LatestPolicy{ExcludeTags: []Pattern{MustParsePattern("^nightly")}}
+
+	assert.True(t, policy.AdmitsTag("v1.0.0"))
+	assert.True(t, policy.AdmitsTag("v1.0.0-rc1"))
+	assert.False(t, policy.AdmitsTag("nightly-20250101"))
+	assert.True(t, policy.AdmitsRelease("v1.0.0", false, false))
+	assert.False(t, policy.AdmitsRelease("v1.0.0", true, false))
+	assert.False(t, policy.AdmitsRelease("v1.0.0", false, true))
+
+	assert.False(t, policy.AdmitsRelease("nightly-20250101", false, false))
+
+	policy = LatestPolicy{IncludePrereleases: &include, IncludeDrafts: &include}
+	assert.True(t, policy.AdmitsRelease("v1.0.0", true, true))
+
+	policy = LatestPolicy{ExcludeTags: []Pattern{MustParsePattern(`-(alpha|beta|rc)`)}}
+	assert.Equal(t, "v1.0.0", policy.FirstAdmittedTag([]string{"v1.1.0-beta", "v1.0.0"}))
+	assert.Empty(t, policy.FirstAdmittedTag([]string{"v1.1.0-beta"}))
+
+	assert.True(t, policy.IsPrereleaseTag("v2.0.0-rc1"))
+	assert.False(t, policy.IsPrereleaseTag("v2.0.0"))
+	assert.False(t, policy.IsPrereleaseTag("nightly-20250101"))
+}
@@ -0,0 +1,73 @@
+package sources
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ResolutionOrder is the order in which a gitRef is tried as each kind of reference, until one is found
+type ResolutionOrder []Kind
+
+// DefaultResolutionOrder tries releases first, as they carry the most details, then tags and commits
+var DefaultResolutionOrder = ResolutionOrder{KindRelease, KindTag, KindCommit}
+
+// ParseResolutionOrder parses a comma separated list of kinds, such as "commit" or "tag,commit"
+func ParseResolutionOrder(s string) (ResolutionOrder, error) {
+	var order ResolutionOrder
+	for _, kind := range strings.Split(s, ",") {
+		order = append(order, Kind(strings.TrimSpace(kind)))
+	}
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Validate checks that the order lists known kinds, each at most once
+func (o ResolutionOrder) Validate() error {
+	for i, kind := range o {
+		switch kind {
+		case KindRelease, KindTag, KindCommit:
+		default:
+			return fmt.Errorf("unknown kind %q", kind)
+		}
+		if slices.Contains(o[:i], kind) {
+			return fmt.Errorf("kind %q listed twice", kind)
+		}
+	}
+	return nil
+}
+
+// For returns the order gitRef is tried in. Obvious commit SHAs are tried as commits first, so that deployments of
+// commits don't pay for failed release and tag lookups, and then as the other kinds of the order.
+func (o ResolutionOrder) For(gitRef string) ResolutionOrder {
+	if !IsObviousSHA(gitRef) || !slices.Contains(o, KindCommit) || o[0] == KindCommit {
+		return o
+	}
+	order := ResolutionOrder{KindCommit}
+	for _, kind := range o {
+		if kind != KindCommit {
+			order = append(order, kind)
+		}
+	}
+	return order
+}
+
+// String returns the order as parsed by ParseResolutionOrder
+func (o ResolutionOrder) String() string {
+	kinds := make([]string, len(o))
+	for i, kind := range o {
+		kinds[i] = string(kind)
+	}
+	return strings.Join(kinds, ",")
+}
+
+// IsObviousSHA reports whether gitRef is a full commit SHA, or an abbreviated one mixing letters and digits.
+// Hexadecimal names made only of letters or only of digits, such as "cafe" or "20240101", may as well name tags.
+func IsObviousSHA(gitRef string) bool {
+	if !IsCommitSHA(gitRef) {
+		return false
+	}
+	return len(gitRef) == 40 || strings.ContainsAny(gitRef, "abcdefABCDEF") && strings.ContainsAny(gitRef, "0123456789")
+}
@@ -0,0 +1,75 @@
+package sources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResolutionOrder(t *testing.T) {
+	order, err := ParseResolutionOrder("tag, commit")
+	assert.NoError(t, err)
+	assert.Equal(t, ResolutionOrder{KindTag, KindCommit}, order)
+	assert.Equal(t, "tag,commit", order.String())
+
+	_, err = ParseResolutionOrder("branch")
+	assert.ErrorContains(t, err, `unknown kind "branch"`)
+
+	_, err = ParseResolutionOrder("commit,commit")
+	assert.ErrorContains(t, err, `kind "commit" listed twice`)
+
+	_, err = ParseResolutionOrder("")
+	assert.Error(t, err)
+}
+
+func TestResolutionOrderFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		order  ResolutionOrder
+		gitRef string
+		want   ResolutionOrder
+	}{
+		{
+			name:   "Version",
+			order:  DefaultResolutionOrder,
+			gitRef: "v1.2.3",
+			want:   ResolutionOrder{KindRelease, KindTag, KindCommit},
+		},
+		{
+			name:   "Abbreviated SHA",
+			order:  DefaultResolutionOrder,
+			gitRef: "dd295fd679",
+			want:   ResolutionOrder{KindCommit, KindRelease, KindTag},
+		},
+		{
+			name:   "Full SHA",
+			order:  ResolutionOrder{KindTag, KindCommit},
+			gitRef: "0123456789012345678901234567890123456789",
+			want:   ResolutionOrder{KindCommit, KindTag},
+		},
+		{
+			name:   "Digits only",
+			order:  DefaultResolutionOrder,
+			gitRef: "20240101",
+			want:   ResolutionOrder{KindRelease, KindTag, KindCommit},
+		},
+		{
+			name:   "Letters only",
+			order:  DefaultResolutionOrder,
+			gitRef: "deadbeef",
+			want:   ResolutionOrder{KindRelease, KindTag, KindCommit},
+		},
+		{
+			name:   "Order without commits",
+			order:  ResolutionOrder{KindRelease},
+			gitRef: "dd295fd679",
+			want:   ResolutionOrder{KindRelease},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.order.For(tt.gitRef))
+		})
+	}
+}
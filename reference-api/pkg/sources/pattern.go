@@ -0,0 +1,71 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Pattern is a regular expression compiled once, when it is parsed or decoded from JSON.
+// The zero Pattern is the empty expression, which matches every string.
+type Pattern struct {
+	re *regexp.Regexp
+}
+
+// ParsePattern compiles the regular expression expr
+func ParsePattern(expr string) (Pattern, error) {
+	if expr == "" {
+		return Pattern{}, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return Pattern{}, err
+	}
+	return Pattern{re: re}, nil
+}
+
+// MustParsePattern is like ParsePattern but panics when expr does not compile
+func MustParsePattern(expr string) Pattern {
+	p, err := ParsePattern(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source text of the expression
+func (p Pattern) String() string {
+	if p.re == nil {
+		return ""
+	}
+	return p.re.String()
+}
+
+// IsZero reports whether the pattern is the empty expression
+func (p Pattern) IsZero() bool {
+	return p.re == nil
+}
+
+// MatchString reports whether s contains a match of the pattern
+func (p Pattern) MatchString(s string) bool {
+	return p.re == nil || p.re.MatchString(s)
+}
+
+// MarshalJSON encodes the pattern as its source text
+func (p Pattern) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON compiles the pattern from its source text, so that invalid expressions are reported when loading
+func (p *Pattern) UnmarshalJSON(data []byte) error {
+	var expr string
+	if err := json.Unmarshal(data, &expr); err != nil {
+		return err
+	}
+	parsed, err := ParsePattern(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %v", expr, err)
+	}
+	*p = parsed
+	return nil
+}
@@ -0,0 +1,25 @@
+package sources
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatternJSON(t *testing.T) {
+	var patterns []Pattern
+	assert.NoError(t, json.Unmarshal([]byte(`["^v1\\.", ""]`), &patterns))
+	if assert.Len(t, patterns, 2) {
+		assert.True(t, patterns[0].MatchString("v1.2.3"))
+		assert.False(t, patterns[0].MatchString("v2.0.0"))
+		assert.True(t, patterns[1].IsZero())
+		assert.True(t, patterns[1].MatchString("anything"))
+	}
+
+	encoded, err := json.Marshal(patterns)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `["^v1\\.", ""]`, string(encoded))
+
+	assert.ErrorContains(t, json.Unmarshal([]byte(`["nightly-("]`), &patterns), `invalid pattern "nightly-("`)
+}
@@ -0,0 +1,62 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+)
+
+// Page selects a page of a paginated listing, numbered from 1
+type Page struct {
+	Number int
+	Size   int
+}
+
+// PendingCommits is a page of the commits that updating from the current to the latest reference would ship
+type PendingCommits struct {
+	Current  *StandardizedEntity   `json:"current"`
+	Latest   *StandardizedEntity   `json:"latest"`
+	Commits  []*StandardizedEntity `json:"commits"`   // Commits of the page, oldest first
+	Page     int                   `json:"page"`      // Number of the page
+	PerPage  int                   `json:"per_page"`  // Size of the page
+	NextPage int                   `json:"next_page"` // Number of the next page, 0 on the last one
+}
+
+// CommitLister is implemented by sources that can list the commits between two references of a repository
+type CommitLister interface {
+	// ListCommitsBetween returns a page of the commits reachable from latest but not from current, oldest first,
+	// and whether more pages follow
+	ListCommitsBetween(ctx context.Context, repo, current, latest string, page Page) ([]*StandardizedEntity, bool, error)
+}
+
+// Pending lists a page of the commits between the current and latest references of a resolved lookup.
+// It returns an error wrapping ErrUnsupported when the source cannot list them.
+func Pending(ctx context.Context, src Source, repo string, output *StandardizedOutput, page Page) (*PendingCommits, error) {
+	lister, ok := src.(CommitLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: the source cannot list commits between references", ErrUnsupported)
+	}
+	if output.Current == nil || output.Latest == nil {
+		return nil, fmt.Errorf("%w: no latest reference for repo %s", ErrNotFound, repo)
+	}
+
+	pending := &PendingCommits{
+		Current: output.Current,
+		Latest:  output.Latest,
+		Commits: []*StandardizedEntity{},
+		Page:    page.Number,
+		PerPage: page.Size,
+	}
+	if output.Current.Ref == output.Latest.Ref {
+		return pending, nil
+	}
+
+	commits, more, err := lister.ListCommitsBetween(ctx, repo, output.Current.Ref, output.Latest.Ref, page)
+	if err != nil {
+		return nil, err
+	}
+	pending.Commits = append(pending.Commits, commits...)
+	if more {
+		pending.NextPage = page.Number + 1
+	}
+	return pending, nil
+}
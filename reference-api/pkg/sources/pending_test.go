@@ -0,0 +1,49 @@
+package sources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPending(t *testing.T) {
+	tags := &taggedSource{tags: []string{"v2.0.0", "v1.0.0"}}
+	output := &StandardizedOutput{
+		Current: &StandardizedEntity{Ref: "v2.0.0"},
+		Latest:  &StandardizedEntity{Ref: "v2.0.0"},
+	}
+
+	_, err := Pending(context.Background(), tags, "org/repo", output, Page{Number: 1, Size: 30})
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	// Nothing is pending when the latest reference is deployed
+	lister := &listingSource{taggedSource: *tags}
+	pending, err := Pending(context.Background(), lister, "org/repo", output, Page{Number: 1, Size: 30})
+	assert.NoError(t, err)
+	assert.Empty(t, pending.Commits)
+	assert.False(t, lister.listed)
+
+	output.Current = &StandardizedEntity{Ref: "v1.0.0"}
+	pending, err = Pending(context.Background(), lister, "org/repo", output, Page{Number: 1, Size: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*StandardizedEntity{{Ref: "v1.0.0..v2.0.0"}}, pending.Commits)
+	assert.Equal(t, 2, pending.NextPage)
+
+	output.Latest = nil
+	_, err = Pending(context.Background(), lister, "org/repo", output, Page{Number: 1, Size: 30})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// listingSource is a taggedSource listing a single commit named after the references, with more pages following
+type listingSource struct {
+	taggedSource
+	listed bool
+}
+
+func (s *listingSource) ListCommitsBetween(
+	ctx context.Context, repo, current, latest string, page Page,
+) ([]*StandardizedEntity, bool, error) {
+	s.listed = true
+	return []*StandardizedEntity{{Ref: current + ".." + latest}}, true, nil
+}
@@ -0,0 +1,27 @@
+package sources
+
+import "context"
+
+// PullRequest is a pull or merge request that shipped a reference
+type PullRequest struct {
+	Number   int    `json:"number"`    // Number of the pull request in its repository
+	Title    string `json:"title"`     // Title of the pull request
+	URL      string `json:"url"`       // Web page of the pull request
+	Author   string `json:"author"`    // Login of the author of the pull request
+	MergedAt string `json:"merged_at"` // When the pull request was merged, empty when it is not
+}
+
+// PullRequestLister is implemented by sources that can find the pull requests associated with a reference
+type PullRequestLister interface {
+	// PullRequestsFor returns the pull requests that the commit ref points to is part of
+	PullRequestsFor(ctx context.Context, repo, ref string) ([]*PullRequest, error)
+}
+
+// pullRequestsFor returns the pull requests associated with the entity when the source can find them
+func pullRequestsFor(ctx context.Context, src Source, repo string, entity *StandardizedEntity) ([]*PullRequest, error) {
+	lister, ok := src.(PullRequestLister)
+	if !ok || entity == nil {
+		return nil, nil
+	}
+	return lister.PullRequestsFor(ctx, repo, entity.Ref)
+}
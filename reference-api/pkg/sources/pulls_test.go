@@ -0,0 +1,37 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pullingSource is a taggedSource whose references were each shipped by the pull requests returned by pulls
+type pullingSource struct {
+	taggedSource
+	pulls func(ref string) ([]*PullRequest, error)
+}
+
+func (s *pullingSource) PullRequestsFor(ctx context.Context, repo, ref string) ([]*PullRequest, error) {
+	return s.pulls(ref)
+}
+
+func TestResolvePullRequests(t *testing.T) {
+	tags := taggedSource{tags: []string{"v2.0.0", "v1.0.0"}}
+
+	source := &pullingSource{taggedSource: tags, pulls: func(ref string) ([]*PullRequest, error) {
+		return []*PullRequest{{Number: 1, Title: "Shipped " + ref}}, nil
+	}}
+	output, err := Resolve(context.Background(), source, Query{GitRef: "v1.0.0", Kind: KindTag})
+	assert.NoError(t, err)
+	assert.Equal(t, []*PullRequest{{Number: 1, Title: "Shipped v1.0.0"}}, output.Current.PullRequests)
+	assert.Nil(t, output.Latest.PullRequests)
+
+	// Failing to find pull requests does not fail the lookup
+	source.pulls = func(string) ([]*PullRequest, error) { return nil, errors.New("lookup failed") }
+	output, err = Resolve(context.Background(), source, Query{GitRef: "v1.0.0", Kind: KindTag})
+	assert.NoError(t, err)
+	assert.Nil(t, output.Current.PullRequests)
+}
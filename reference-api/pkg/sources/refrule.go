@@ -0,0 +1,71 @@
+package sources
+
+import "fmt"
+
+// RefRule extracts the gitRef to resolve from the image tags matching its pattern, such as "1.4.2-abcdef0"
+type RefRule struct {
+	Name    string  `json:"name"`    // Reported in the response when the rule matches, defaults to the pattern
+	Pattern Pattern `json:"pattern"` // Regular expression with a "tag" and/or a "sha" named group
+}
+
+// Extraction describes what the rule matching an image tag extracted from it
+type Extraction struct {
+	Rule string `json:"rule"`          // Name of the matching rule
+	Tag  string `json:"tag,omitempty"` // Release or tag captured by the "tag" group
+	SHA  string `json:"sha,omitempty"` // Commit SHA captured by the "sha" group
+}
+
+// Validate reports whether the rule can be applied
+func (r RefRule) Validate() error {
+	re := r.Pattern.re
+	if re == nil || re.SubexpIndex("tag") < 0 && re.SubexpIndex("sha") < 0 {
+		return fmt.Errorf("pattern %q has neither a \"tag\" nor a \"sha\" named group", r.Pattern)
+	}
+	return nil
+}
+
+// ExtractRef applies the first of rules matching imageTag and capturing a tag or SHA.
+// It reports false when none does.
+func ExtractRef(rules []RefRule, imageTag string) (*Extraction, bool) {
+	for _, rule := range rules {
+		re := rule.Pattern.re
+		if re == nil {
+			continue
+		}
+		match := re.FindStringSubmatch(imageTag)
+		if match == nil {
+			continue
+		}
+
+		extraction := &Extraction{Rule: rule.Name}
+		if extraction.Rule == "" {
+			extraction.Rule = rule.Pattern.String()
+		}
+		if i := re.SubexpIndex("tag"); i >= 0 {
+			extraction.Tag = match[i]
+		}
+		if i := re.SubexpIndex("sha"); i >= 0 {
+			extraction.SHA = match[i]
+		}
+		if extraction.Tag != "" || extraction.SHA != "" {
+			return extraction, true
+		}
+	}
+	return nil, false
+}
+
+// GitRef returns the gitRef to resolve, the tag when one was captured and the SHA otherwise
+func (e *Extraction) GitRef() string {
+	if e.Tag != "" {
+		return e.Tag
+	}
+	return e.SHA
+}
+
+// FallbackSHA returns the SHA to resolve as a commit when the captured tag is not found, or "" if there is none
+func (e *Extraction) FallbackSHA() string {
+	if e.Tag == "" {
+		return ""
+	}
+	return e.SHA
+}
@@ -0,0 +1,64 @@
+package sources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRef(t *testing.T) {
+	rules := []RefRule{
+		{Name: "version-sha", Pattern: MustParsePattern(`^(?P<tag>\d+\.\d+\.\d+)-(?P<sha>[0-9a-f]{7,40})$`)},
+		{Name: "branch-sha-date", Pattern: MustParsePattern(`^[a-z]+-(?P<sha>[0-9a-f]{7,40})-\d{8}$`)},
+		{Pattern: MustParsePattern(`^sha-(?P<sha>[0-9a-f]{7,40})$`)},
+		{Name: "optional-tag", Pattern: MustParsePattern(`^build(?P<tag>-v[0-9.]+)?$`)},
+	}
+
+	tests := []struct {
+		name     string
+		imageTag string
+		want     *Extraction
+	}{
+		{
+			name:     "Tag and SHA",
+			imageTag: "1.4.2-abcdef0",
+			want:     &Extraction{Rule: "version-sha", Tag: "1.4.2", SHA: "abcdef0"},
+		},
+		{
+			name:     "SHA between branch and date",
+			imageTag: "main-abcdef0-20240101",
+			want:     &Extraction{Rule: "branch-sha-date", SHA: "abcdef0"},
+		},
+		{
+			name:     "Unnamed rule is reported by its pattern",
+			imageTag: "sha-abcdef0",
+			want:     &Extraction{Rule: `^sha-(?P<sha>[0-9a-f]{7,40})$`, SHA: "abcdef0"},
+		},
+		{name: "Rule capturing nothing", imageTag: "build"},
+		{name: "No matching rule", imageTag: "v1.2.3--release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractRef(rules, tt.imageTag)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want != nil, ok)
+		})
+	}
+}
+
+func TestExtractionGitRef(t *testing.T) {
+	both := &Extraction{Tag: "1.4.2", SHA: "abcdef0"}
+	assert.Equal(t, "1.4.2", both.GitRef())
+	assert.Equal(t, "abcdef0", both.FallbackSHA())
+
+	shaOnly := &Extraction{SHA: "abcdef0"}
+	assert.Equal(t, "abcdef0", shaOnly.GitRef())
+	assert.Empty(t, shaOnly.FallbackSHA())
+}
+
+func TestRefRuleValidate(t *testing.T) {
+	assert.NoError(t, RefRule{Pattern: MustParsePattern(`^(?P<tag>v.+)$`)}.Validate())
+	assert.ErrorContains(t, RefRule{}.Validate(), "named group")
+	assert.ErrorContains(t, RefRule{Pattern: MustParsePattern(`^(?P<version>v.+)$`)}.Validate(), "named group")
+}
@@ -0,0 +1,71 @@
+package sources
+
+import (
+	"sort"
+	"strings"
+)
+
+// Registry routes repositories to the Source that hosts them
+type Registry struct {
+	fallback Source
+	routes   []route
+}
+
+type route struct {
+	prefix    string
+	source    Source
+	stripHost bool // Whether the matched prefix is removed before the source sees the repository
+}
+
+// NewRegistry creates a Registry that sends repositories without a matching route to fallback.
+// fallback may be nil, in which case unmatched repositories have no source.
+func NewRegistry(fallback Source) *Registry {
+	return &Registry{fallback: fallback}
+}
+
+// RegisterHost routes repositories of the form "<host>/<path>" to src, which is queried with "<path>"
+func (r *Registry) RegisterHost(host string, src Source) {
+	r.add(route{prefix: host, source: src, stripHost: true})
+}
+
+// RegisterPrefix routes repositories starting with prefix to src, which is queried with the unchanged repository
+func (r *Registry) RegisterPrefix(prefix string, src Source) {
+	r.add(route{prefix: prefix, source: src})
+}
+
+func (r *Registry) add(rt route) {
+	rt.prefix = strings.Trim(rt.prefix, "/")
+	r.routes = append(r.routes, rt)
+
+	// Keep the most specific prefix first so that it wins over broader ones
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		return len(r.routes[i].prefix) > len(r.routes[j].prefix)
+	})
+}
+
+// Lookup returns the source for repo along with the repository path it should be queried with.
+// Prefixes only match whole path segments, so "org" matches "org/repo" but not "organization/repo".
+func (r *Registry) Lookup(repo string) (Source, string) {
+	for _, rt := range r.routes {
+		if repo != rt.prefix && !strings.HasPrefix(repo, rt.prefix+"/") {
+			continue
+		}
+		if rt.stripHost {
+			return rt.source, strings.TrimPrefix(strings.TrimPrefix(repo, rt.prefix), "/")
+		}
+		return rt.source, repo
+	}
+	return r.fallback, repo
+}
+
+// Sources returns every source keyed by the prefix routed to it, with the fallback source under ""
+func (r *Registry) Sources() map[string]Source {
+	all := make(map[string]Source, len(r.routes)+1)
+	if r.fallback != nil {
+		all[""] = r.fallback
+	}
+	for _, rt := range r.routes {
+		all[rt.prefix] = rt.source
+	}
+	return all
+}
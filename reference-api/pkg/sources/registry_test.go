@@ -0,0 +1,98 @@
+package sources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// namedSource is a Source stub that is only compared by identity
+type namedSource struct {
+	name string
+}
+
+func (n *namedSource) ResolveCurrent(ctx context.Context, q Query) (*StandardizedEntity, error) {
+	return nil, ErrNotFound
+}
+
+func (n *namedSource) ResolveLatest(ctx context.Context, q Query) (*StandardizedEntity, error) {
+	return nil, ErrNotFound
+}
+
+func TestRegistryLookup(t *testing.T) {
+	fallback := &namedSource{name: "fallback"}
+	gitlab := &namedSource{name: "gitlab"}
+	team := &namedSource{name: "team"}
+	teamApp := &namedSource{name: "team-app"}
+
+	registry := NewRegistry(fallback)
+	registry.RegisterHost("gitlab.example.com", gitlab)
+	registry.RegisterPrefix("team", team)
+	registry.RegisterPrefix("team/app/", teamApp)
+
+	tests := []struct {
+		name     string
+		repo     string
+		want     Source
+		wantRepo string
+	}{
+		{
+			name:     "Unrouted repository uses the fallback",
+			repo:     "mozilla/repo",
+			want:     fallback,
+			wantRepo: "mozilla/repo",
+		},
+		{
+			name:     "Host route strips the host",
+			repo:     "gitlab.example.com/group/subgroup/project",
+			want:     gitlab,
+			wantRepo: "group/subgroup/project",
+		},
+		{
+			name:     "Prefix route keeps the repository unchanged",
+			repo:     "team/service",
+			want:     team,
+			wantRepo: "team/service",
+		},
+		{
+			name:     "Longest prefix wins",
+			repo:     "team/app",
+			want:     teamApp,
+			wantRepo: "team/app",
+		},
+		{
+			name:     "Prefix only matches whole path segments",
+			repo:     "teammates/repo",
+			want:     fallback,
+			wantRepo: "teammates/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotRepo := registry.Lookup(tt.repo)
+			assert.Same(t, tt.want, got)
+			assert.Equal(t, tt.wantRepo, gotRepo)
+		})
+	}
+}
+
+func TestRegistryWithoutFallback(t *testing.T) {
+	registry := NewRegistry(nil)
+	registry.RegisterHost("gitlab.example.com", &namedSource{name: "gitlab"})
+
+	got, _ := registry.Lookup("mozilla/repo")
+	assert.Nil(t, got)
+}
+
+func TestRegistrySources(t *testing.T) {
+	fallback := &namedSource{name: "fallback"}
+	gitlab := &namedSource{name: "gitlab"}
+
+	registry := NewRegistry(fallback)
+	registry.RegisterHost("gitlab.example.com/", gitlab)
+
+	assert.Equal(t, map[string]Source{"": fallback, "gitlab.example.com": gitlab}, registry.Sources())
+	assert.Empty(t, NewRegistry(nil).Sources())
+}
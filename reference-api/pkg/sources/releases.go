@@ -0,0 +1,119 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ReleaseLister is implemented by sources with releases that can list them
+type ReleaseLister interface {
+	// ListReleases returns every release of repo with its notes and flags, most recently published first
+	ListReleases(ctx context.Context, repo string) ([]*StandardizedEntity, error)
+}
+
+// PendingReleases are the releases published after the current reference and before the latest one
+type PendingReleases struct {
+	Current  *StandardizedEntity   `json:"current"`
+	Latest   *StandardizedEntity   `json:"latest"`
+	Releases []*StandardizedEntity `json:"releases"` // Releases between current and latest, newest first
+}
+
+// ReleasesBetween returns the releases strictly between the current and latest references of a resolved lookup.
+// They are ordered as q.Latest chooses latest, and releases it never counts as latest, such as prereleases and
+// drafts by default, are left out. It returns an error wrapping ErrUnsupported when the source has no releases.
+func ReleasesBetween(ctx context.Context, src Source, q Query, output *StandardizedOutput) (*PendingReleases, error) {
+	lister, ok := src.(ReleaseLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: the source has no releases", ErrUnsupported)
+	}
+	if output.Current == nil || output.Latest == nil {
+		return nil, fmt.Errorf("%w: no latest reference for repo %s", ErrNotFound, q.Repo)
+	}
+
+	releases, err := lister.ListReleases(ctx, q.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := q.Latest
+	policy.scope = q.Scope
+	between, ok := releasesByVersion(releases, output.Current.Ref, output.Latest.Ref, policy)
+	if !ok {
+		between, err = releasesByDate(releases, output.Current.Ref, output.Latest.Ref, policy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &PendingReleases{
+		Current:  output.Current,
+		Latest:   output.Latest,
+		Releases: between,
+	}, nil
+}
+
+// releasesByVersion returns the admitted releases whose version lies between those of current and latest, highest
+// first. It reports false when the policy does not choose latest by version or either reference is not a version.
+func releasesByVersion(releases []*StandardizedEntity, current, latest string, policy LatestPolicy) ([]*StandardizedEntity, bool) {
+	if policy.Strategy != LatestByVersion {
+		return nil, false
+	}
+	currentVersion, ok := policy.parseVersion(current)
+	if !ok {
+		return nil, false
+	}
+	latestVersion, ok := policy.parseVersion(latest)
+	if !ok {
+		return nil, false
+	}
+
+	type candidate struct {
+		release *StandardizedEntity
+		version Version
+	}
+	var candidates []candidate
+	for _, release := range releases {
+		version, ok := policy.parseVersion(release.Ref)
+		if !ok || version.Compare(currentVersion) <= 0 || version.Compare(latestVersion) >= 0 {
+			continue
+		}
+		if policy.AdmitsRelease(release.Ref, release.Prerelease || version.Prerelease != "", release.Draft) {
+			candidates = append(candidates, candidate{release: release, version: version})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].version.Compare(candidates[j].version) > 0
+	})
+
+	between := make([]*StandardizedEntity, len(candidates))
+	for i, c := range candidates {
+		between[i] = c.release
+	}
+	return between, true
+}
+
+// releasesByDate returns the admitted releases published after current and before latest, newest first.
+// Releases newer than every other one are pending when latest is a tag without a release.
+func releasesByDate(releases []*StandardizedEntity, current, latest string, policy LatestPolicy) ([]*StandardizedEntity, error) {
+	start, end := 0, -1
+	for i, release := range releases {
+		if release.Ref == latest {
+			start = i + 1
+		}
+		if release.Ref == current {
+			end = i
+		}
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("%w: no release found for gitRef %s", ErrNotFound, current)
+	}
+
+	between := []*StandardizedEntity{}
+	for _, release := range releases[min(start, end):end] {
+		if policy.AdmitsRelease(release.Ref, release.Prerelease, release.Draft) {
+			between = append(between, release)
+		}
+	}
+	return between, nil
+}
@@ -0,0 +1,101 @@
+package sources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// releasedSource is a taggedSource listing the given releases
+type releasedSource struct {
+	taggedSource
+	list []*StandardizedEntity
+}
+
+func (s *releasedSource) ListReleases(ctx context.Context, repo string) ([]*StandardizedEntity, error) {
+	return s.list, nil
+}
+
+func TestReleasesBetween(t *testing.T) {
+	source := &releasedSource{list: []*StandardizedEntity{
+		// Most recently published first, with a backported patch published after v2.0.0
+		{Ref: "v1.4.9"},
+		{Ref: "v2.1.0-rc.1", Prerelease: true},
+		{Ref: "v2.0.0"},
+		{Ref: "v1.5.0", Draft: true},
+		{Ref: "v1.4.0"},
+		{Ref: "v1.3.0"},
+	}}
+
+	include := true
+	tests := []struct {
+		name    string
+		query   Query
+		current string
+		latest  string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "By date skips prereleases and drafts",
+			current: "v1.3.0",
+			latest:  "v1.4.9",
+			want:    []string{"v2.0.0", "v1.4.0"},
+		},
+		{
+			name:    "By date with prereleases",
+			query:   Query{Latest: LatestPolicy{IncludePrereleases: &include}},
+			current: "v1.3.0",
+			latest:  "v1.4.9",
+			want:    []string{"v2.1.0-rc.1", "v2.0.0", "v1.4.0"},
+		},
+		{
+			name:    "By version",
+			query:   Query{Latest: LatestPolicy{Strategy: LatestByVersion, IncludeDrafts: &include}},
+			current: "v1.3.0",
+			latest:  "v2.0.0",
+			want:    []string{"v1.5.0", "v1.4.9", "v1.4.0"},
+		},
+		{
+			name:    "Latest tag without a release",
+			current: "v1.4.0",
+			latest:  "v3.0.0",
+			want:    []string{"v1.4.9", "v2.0.0"},
+		},
+		{
+			name:    "Latest deployed",
+			current: "v1.4.9",
+			latest:  "v1.4.9",
+			want:    []string{},
+		},
+		{
+			name:    "Current reference without a release",
+			current: "v1.2.0",
+			latest:  "v1.4.9",
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReleasesBetween(context.Background(), source, tt.query, &StandardizedOutput{
+				Current: &StandardizedEntity{Ref: tt.current},
+				Latest:  &StandardizedEntity{Ref: tt.latest},
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			refs := []string{}
+			for _, release := range got.Releases {
+				refs = append(refs, release.Ref)
+			}
+			assert.Equal(t, tt.want, refs)
+		})
+	}
+
+	_, err := ReleasesBetween(context.Background(), &taggedSource{}, Query{}, &StandardizedOutput{})
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
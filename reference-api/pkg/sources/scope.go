@@ -0,0 +1,37 @@
+package sources
+
+import "strings"
+
+// TagScope limits the tags a lookup considers to a single release stream, such as the tags of one service of a monorepo
+type TagScope struct {
+	Prefix  string  `json:"tagPrefix"`  // Tags of the stream start with Prefix, which is added to gitRefs lacking it
+	Pattern Pattern `json:"tagPattern"` // Regular expression the tags of the stream match
+}
+
+// Override returns the scope with the fields set in override replacing its own
+func (s TagScope) Override(override TagScope) TagScope {
+	if override.Prefix != "" {
+		s.Prefix = override.Prefix
+	}
+	if !override.Pattern.IsZero() {
+		s.Pattern = override.Pattern
+	}
+	return s
+}
+
+// Matches reports whether the tag name belongs to the release stream
+func (s TagScope) Matches(name string) bool {
+	if !strings.HasPrefix(name, s.Prefix) {
+		return false
+	}
+	return s.Pattern.MatchString(name)
+}
+
+// Apply returns the name of the tag of the stream that gitRef refers to, adding the prefix when gitRef lacks it.
+// It reports false when that tag does not belong to the stream.
+func (s TagScope) Apply(gitRef string) (string, bool) {
+	if !strings.HasPrefix(gitRef, s.Prefix) {
+		gitRef = s.Prefix + gitRef
+	}
+	return gitRef, s.Matches(gitRef)
+}
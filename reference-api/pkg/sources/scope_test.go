@@ -0,0 +1,64 @@
+package sources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagScopeApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		scope  TagScope
+		gitRef string
+		want   string
+		wantOK bool
+	}{
+		{name: "No scope", gitRef: "v1.2.3", want: "v1.2.3", wantOK: true},
+		{name: "Prefix added", scope: TagScope{Prefix: "api/"}, gitRef: "v1.2.3", want: "api/v1.2.3", wantOK: true},
+		{name: "Prefix kept", scope: TagScope{Prefix: "api/"}, gitRef: "api/v1.2.3", want: "api/v1.2.3", wantOK: true},
+		{
+			name:   "Matching pattern",
+			scope:  TagScope{Pattern: MustParsePattern(`^worker/v\d+`)},
+			gitRef: "worker/v0.9.1",
+			want:   "worker/v0.9.1",
+			wantOK: true,
+		},
+		{name: "Pattern not matched", scope: TagScope{Pattern: MustParsePattern(`^worker/`)}, gitRef: "api/v1.2.3", want: "api/v1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.scope.Apply(tt.gitRef)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestResolveScoped(t *testing.T) {
+	source := &taggedSource{
+		// Newest first, across the services of a monorepo
+		tags:     []string{"worker/v0.9.1", "api/v1.2.3", "api/v1.10.0", "v3.0.0"},
+		releases: map[string]bool{"api/v1.2.3": true, "api/v1.10.0": true},
+	}
+	scope := TagScope{Prefix: "api/"}
+
+	output, err := Resolve(context.Background(), source, Query{
+		GitRef: "v1.2.3",
+		Kind:   KindRelease,
+		Latest: LatestPolicy{Strategy: LatestByVersion},
+		Scope:  scope,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &StandardizedEntity{Ref: "api/v1.2.3", Message: "release"}, output.Current)
+	assert.Equal(t, &StandardizedEntity{Ref: "api/v1.10.0", Message: "release"}, output.Latest)
+
+	_, err = Resolve(context.Background(), source, Query{
+		GitRef: "worker/v0.9.1",
+		Kind:   KindTag,
+		Scope:  TagScope{Pattern: MustParsePattern("^api/")},
+	})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
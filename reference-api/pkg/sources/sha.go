@@ -0,0 +1,59 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrAmbiguousRef is returned when an abbreviated commit SHA matches more than one commit
+var ErrAmbiguousRef = errors.New("ambiguous gitRef")
+
+// AmbiguousRefError lists the commits an abbreviated commit SHA matches
+type AmbiguousRefError struct {
+	Prefix     string
+	Candidates []string // Full SHAs of the matching commits, empty when the source cannot list them
+}
+
+func (e *AmbiguousRefError) Error() string {
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("%v: %s matches several commits", ErrAmbiguousRef, e.Prefix)
+	}
+	return fmt.Sprintf("%v: %s matches commits %s", ErrAmbiguousRef, e.Prefix, strings.Join(e.Candidates, ", "))
+}
+
+func (e *AmbiguousRefError) Unwrap() error {
+	return ErrAmbiguousRef
+}
+
+// CommitExpander is implemented by sources that can expand abbreviated commit SHAs
+type CommitExpander interface {
+	// ExpandCommitSHA returns the full SHA of the only commit whose SHA starts with prefix.
+	// It returns an error wrapping ErrNotFound when no commit matches and an *AmbiguousRefError when several do.
+	ExpandCommitSHA(ctx context.Context, repo, prefix string) (string, error)
+}
+
+// IsAbbreviatedSHA reports whether gitRef looks like a commit SHA shorter than a full one
+func IsAbbreviatedSHA(gitRef string) bool {
+	return len(gitRef) < 40 && IsCommitSHA(gitRef)
+}
+
+// ExpandCommitSHA returns the full SHA of the commit gitRef abbreviates.
+// gitRef is returned unchanged when it is not an abbreviated SHA, when no commit matches it, as it may still name a
+// tag, and when the source cannot expand SHAs.
+func ExpandCommitSHA(ctx context.Context, src Source, repo, gitRef string) (string, error) {
+	expander, ok := src.(CommitExpander)
+	if !ok || !IsAbbreviatedSHA(gitRef) {
+		return gitRef, nil
+	}
+
+	sha, err := expander.ExpandCommitSHA(ctx, repo, strings.ToLower(gitRef))
+	if errors.Is(err, ErrNotFound) {
+		return gitRef, nil
+	}
+	if err != nil {
+		return gitRef, err
+	}
+	return strings.ToLower(sha), nil
+}
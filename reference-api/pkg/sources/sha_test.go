@@ -0,0 +1,72 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// expandingSource is a taggedSource expanding abbreviated SHAs with expand
+type expandingSource struct {
+	taggedSource
+	expand func(prefix string) (string, error)
+}
+
+func (s *expandingSource) ExpandCommitSHA(ctx context.Context, repo, prefix string) (string, error) {
+	return s.expand(prefix)
+}
+
+func TestExpandCommitSHA(t *testing.T) {
+	const fullSHA = "abc1234def567890abc1234def567890abc1234d"
+	source := &expandingSource{expand: func(prefix string) (string, error) {
+		switch prefix {
+		case "abc1234":
+			return fullSHA, nil
+		case "fedcba9":
+			return "", &AmbiguousRefError{Prefix: prefix, Candidates: []string{"fedcba90", "fedcba91"}}
+		case "0000000":
+			return "", errors.New("lookup failed")
+		}
+		return "", ErrNotFound
+	}}
+
+	tests := []struct {
+		name    string
+		source  Source
+		gitRef  string
+		want    string
+		wantErr error
+	}{
+		{name: "Abbreviated SHA", source: source, gitRef: "abc1234", want: fullSHA},
+		{name: "Uppercase abbreviated SHA", source: source, gitRef: "ABC1234", want: fullSHA},
+		{name: "Full SHA", source: source, gitRef: fullSHA, want: fullSHA},
+		{name: "Tag", source: source, gitRef: "v1.0.0", want: "v1.0.0"},
+		{name: "Hex tag matching no commit", source: source, gitRef: "deadbeef", want: "deadbeef"},
+		{name: "Ambiguous SHA", source: source, gitRef: "fedcba9", want: "fedcba9", wantErr: ErrAmbiguousRef},
+		{name: "Failed expansion", source: source, gitRef: "0000000", want: "0000000", wantErr: errors.New("lookup failed")},
+		{name: "Source unable to expand", source: &taggedSource{}, gitRef: "abc1234", want: "abc1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandCommitSHA(context.Background(), tt.source, "org/repo", tt.gitRef)
+			assert.Equal(t, tt.want, got)
+			switch {
+			case tt.wantErr == nil:
+				assert.NoError(t, err)
+			case errors.Is(tt.wantErr, ErrAmbiguousRef):
+				assert.ErrorIs(t, err, ErrAmbiguousRef)
+			default:
+				assert.EqualError(t, err, tt.wantErr.Error())
+			}
+		})
+	}
+}
+
+func TestAmbiguousRefError(t *testing.T) {
+	err := &AmbiguousRefError{Prefix: "fedcba9", Candidates: []string{"fedcba90", "fedcba91"}}
+	assert.EqualError(t, err, "ambiguous gitRef: fedcba9 matches commits fedcba90, fedcba91")
+	assert.EqualError(t, &AmbiguousRefError{Prefix: "fedcba9"}, "ambiguous gitRef: fedcba9 matches several commits")
+}
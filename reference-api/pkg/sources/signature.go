@@ -0,0 +1,23 @@
+package sources
+
+import "fmt"
+
+// Verification describes the signature of a commit or annotated tag as checked by the source
+type Verification struct {
+	Verified bool   `json:"verified"` // Whether the signature was verified
+	Reason   string `json:"reason"`   // Why the signature is verified or not, e.g. "valid" or "unsigned"
+	Signer   string `json:"signer"`   // Who signed the object, empty when it is unsigned
+}
+
+// signatureWarning describes why the entity is not known to carry a verified signature, or returns "" when it does
+func signatureWarning(entity *StandardizedEntity) string {
+	switch {
+	case entity.Verification == nil:
+		return fmt.Sprintf("the source cannot verify the signature of %s", entity.Ref)
+	case entity.Verification.Reason == "unsigned":
+		return fmt.Sprintf("%s is not signed", entity.Ref)
+	case !entity.Verification.Verified:
+		return fmt.Sprintf("the signature of %s is not verified: %s", entity.Ref, entity.Verification.Reason)
+	}
+	return ""
+}
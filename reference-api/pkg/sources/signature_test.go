@@ -0,0 +1,69 @@
+package sources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// signedSource is a taggedSource whose references are all signed as described by verification
+type signedSource struct {
+	taggedSource
+	verification *Verification
+}
+
+func (s *signedSource) ResolveCurrent(ctx context.Context, q Query) (*StandardizedEntity, error) {
+	entity, err := s.taggedSource.ResolveCurrent(ctx, q)
+	if entity != nil {
+		entity.Verification = s.verification
+	}
+	return entity, err
+}
+
+func TestResolveSignatureWarning(t *testing.T) {
+	tags := taggedSource{tags: []string{"v2.0.0", "v1.0.0"}}
+
+	tests := []struct {
+		name         string
+		verification *Verification
+		require      bool
+		want         string
+	}{
+		{
+			name:         "Verified",
+			verification: &Verification{Verified: true, Reason: "valid", Signer: "octocat"},
+			require:      true,
+		},
+		{
+			name:         "Unsigned",
+			verification: &Verification{Reason: "unsigned"},
+			require:      true,
+			want:         "v1.0.0 is not signed",
+		},
+		{
+			name:         "Unverified",
+			verification: &Verification{Reason: "unknown_key", Signer: "octocat"},
+			require:      true,
+			want:         "the signature of v1.0.0 is not verified: unknown_key",
+		},
+		{
+			name:    "Source without signatures",
+			require: true,
+			want:    "the source cannot verify the signature of v1.0.0",
+		},
+		{
+			name:         "Signatures not required",
+			verification: &Verification{Reason: "unsigned"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &signedSource{taggedSource: tags, verification: tt.verification}
+			output, err := Resolve(context.Background(), source, Query{GitRef: "v1.0.0", Kind: KindTag, RequireSignature: tt.require})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, output.SignatureWarning)
+		})
+	}
+}
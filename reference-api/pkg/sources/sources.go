@@ -0,0 +1,115 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+)
+
+var (
+	// ErrNotFound is returned when a reference does not exist as the requested kind
+	ErrNotFound = errors.New("reference not found")
+	// ErrInvalidRef is returned when a gitRef can never be resolved as the requested kind
+	ErrInvalidRef = errors.New("invalid gitRef")
+	// ErrUnsupported is returned when a source cannot perform an optional lookup
+	ErrUnsupported = errors.New("unsupported by source")
+)
+
+var commitHashRegex = regexp.MustCompile(`^[a-fA-F0-9]{7,40}$`)
+
+// IsCommitSHA reports whether gitRef looks like a full or abbreviated commit SHA
+func IsCommitSHA(gitRef string) bool {
+	return commitHashRegex.MatchString(gitRef)
+}
+
+// Kind identifies the type of git reference a gitRef is resolved as
+type Kind string
+
+const (
+	KindRelease Kind = "release"
+	KindTag     Kind = "tag"
+	KindCommit  Kind = "commit"
+)
+
+// Query describes a single reference lookup against a Source
+type Query struct {
+	Repo   string // Repository path as understood by the source, e.g. "owner/repo"
+	GitRef string // Reference being resolved, with any image tag metadata already stripped
+	Kind   Kind   // Kind of reference GitRef is resolved as
+
+	Latest LatestPolicy // How the latest reference is chosen
+	Scope  TagScope     // Release stream that releases and tags, current and latest, are limited to
+	Branch string       // Branch whose head is the latest commit. Empty for the default branch
+
+	RequireSignature bool // Whether a current reference without a verified signature is flagged
+}
+
+// Source resolves git references for repositories hosted on a single backend
+type Source interface {
+	// ResolveCurrent resolves q.GitRef as a reference of kind q.Kind.
+	// It returns an error wrapping ErrNotFound when the reference does not exist as that kind.
+	ResolveCurrent(ctx context.Context, q Query) (*StandardizedEntity, error)
+
+	// ResolveLatest returns the newest reference that a reference of kind q.Kind should be compared against.
+	// For commits it is the head of q.Branch.
+	ResolveLatest(ctx context.Context, q Query) (*StandardizedEntity, error)
+}
+
+// Resolve looks up the current reference and pairs it with the latest one.
+// Failures to determine the latest reference, to compare it with the current one, to find the pull requests that
+// shipped the current one, or to list the CI checks of either are logged and do not fail the lookup.
+func Resolve(ctx context.Context, src Source, q Query) (*StandardizedOutput, error) {
+	if q.Kind != KindCommit {
+		gitRef, ok := q.Scope.Apply(q.GitRef)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s %s is outside the release stream", ErrNotFound, q.Kind, gitRef)
+		}
+		q.GitRef = gitRef
+	}
+
+	current, err := src.ResolveCurrent(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	pulls, err := pullRequestsFor(ctx, src, q.Repo, current)
+	if err != nil {
+		log.Printf("Error fetching pull requests of %s for %s: %v", q.GitRef, q.Repo, err)
+	}
+	current.PullRequests = pulls // Allow partial results
+
+	latest, err := ResolveLatest(ctx, src, q)
+	if err != nil {
+		log.Printf("Error fetching latest %s for %s: %v", q.Kind, q.Repo, err)
+		latest = nil // Allow partial results
+	}
+
+	for _, entity := range []*StandardizedEntity{current, latest} {
+		if entity == nil {
+			continue
+		}
+		checks, err := checksFor(ctx, src, q.Repo, entity)
+		if err != nil {
+			log.Printf("Error fetching checks of %s for %s: %v", entity.Ref, q.Repo, err)
+		}
+		entity.Checks = checks // Allow partial results
+	}
+
+	comparison, err := compare(ctx, src, q.Repo, current, latest)
+	if err != nil {
+		log.Printf("Error comparing %s with latest for %s: %v", q.GitRef, q.Repo, err)
+		comparison = nil // Allow partial results
+	}
+
+	output := &StandardizedOutput{
+		Latest:     latest,
+		Current:    current,
+		Comparison: comparison,
+	}
+	if q.RequireSignature {
+		output.SignatureWarning = signatureWarning(current)
+	}
+	return output, nil
+}
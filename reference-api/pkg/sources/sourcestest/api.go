@@ -0,0 +1,93 @@
+// Package sourcestest provides in-process stand-ins of the forge APIs sources read, for tests
+package sourcestest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// API describes a stand-in of the API of a forge, answering requests with canned responses by path
+type API struct {
+	// Prefix is prepended to the paths responses are served at, such as "/api/v3"
+	Prefix string
+
+	// Header names the header requests must carry Authorization in, else they are answered with a 401.
+	// Requests are not checked when it is empty.
+	Header        string
+	Authorization string
+
+	// NotFound is the body of the 404 answered to paths without a response
+	NotFound string
+
+	// EscapedPaths matches the escaped paths of requests, for APIs taking escaped path segments such as GitLab
+	EscapedPaths bool
+}
+
+// Serve starts a server answering requests with the handler of their path, closed when the test ends.
+// Paths ending in "/" also answer the paths below them, as the patterns of http.ServeMux do.
+func (api API) Serve(t testing.TB, handlers map[string]http.HandlerFunc) *httptest.Server {
+	return NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if api.Header != "" && r.Header.Get(api.Header) != api.Authorization {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		path := r.URL.Path
+		if api.EscapedPaths {
+			path = r.URL.EscapedPath()
+		}
+		handler := api.route(handlers, path)
+		if handler == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, api.NotFound)
+			return
+		}
+		handler(w, r)
+	}))
+}
+
+// ServeJSON starts a server answering requests with the JSON body of their path, closed when the test ends
+func (api API) ServeJSON(t testing.TB, responses map[string]string) *httptest.Server {
+	handlers := make(map[string]http.HandlerFunc, len(responses))
+	for path, body := range responses {
+		handlers[path] = JSON(body)
+	}
+	return api.Serve(t, handlers)
+}
+
+// route returns the handler of path, or of the longest path ending in "/" it is below. It returns nil when none
+// matches.
+func (api API) route(handlers map[string]http.HandlerFunc, path string) http.HandlerFunc {
+	path, ok := strings.CutPrefix(path, api.Prefix)
+	if !ok {
+		return nil
+	}
+	if handler, ok := handlers[path]; ok {
+		return handler
+	}
+	var longest string
+	var match http.HandlerFunc
+	for pattern, handler := range handlers {
+		if strings.HasSuffix(pattern, "/") && strings.HasPrefix(path, pattern) && len(pattern) > len(longest) {
+			longest, match = pattern, handler
+		}
+	}
+	return match
+}
+
+// JSON returns a handler replying with the given JSON body
+func JSON(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, body)
+	}
+}
+
+// NewServer starts an httptest server for handler that is closed when the test ends
+func NewServer(t testing.TB, handler http.Handler) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
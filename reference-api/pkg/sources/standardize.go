@@ -0,0 +1,35 @@
+package sources
+
+// Create a standardized struct for Commits and Releases
+type StandardizedOutput struct {
+	Latest     *StandardizedEntity `json:"latest"`
+	Current    *StandardizedEntity `json:"current"`
+	Comparison *Comparison         `json:"comparison"` // Distance from current to latest, when the source can tell
+
+	// SignatureWarning flags a current reference without a verified signature when signatures are required
+	SignatureWarning string `json:"signature_warning,omitempty"`
+
+	// Extraction tells which configured rule the gitRef was extracted from the image tag by, for debugging
+	Extraction *Extraction `json:"extraction,omitempty"`
+}
+
+// Kinds of tags. Annotated tags are objects of their own, with a tagger, date and message.
+const (
+	TagAnnotated   = "annotated"
+	TagLightweight = "lightweight"
+)
+
+type StandardizedEntity struct {
+	Ref         string `json:"ref"`          // Commit SHA or Release Tag
+	URL         string `json:"url"`          // Commit URL or Release URL
+	Message     string `json:"message"`      // Commit Message or Release Body
+	Author      string `json:"author"`       // Commit Author Login or Release Author Login
+	PublishedAt string `json:"published_at"` // Commit Date or Release Published At
+	Prerelease  bool   `json:"prerelease"`   // Release flagged as a prerelease
+	Draft       bool   `json:"draft"`        // Release not yet published
+
+	TagKind      string         `json:"tag_kind,omitempty"`      // TagAnnotated or TagLightweight for tags, empty when unknown
+	PullRequests []*PullRequest `json:"pull_requests,omitempty"` // Pull requests that shipped the current reference
+	Checks       *Checks        `json:"checks,omitempty"`        // CI checks reported for the commit of the reference
+	Verification *Verification  `json:"verification,omitempty"`  // Signature of the commit or annotated tag
+}
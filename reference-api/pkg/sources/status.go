@@ -0,0 +1,24 @@
+package sources
+
+import "context"
+
+// Status describes the health of a source's configuration
+type Status struct {
+	Type     string `json:"type,omitempty"`     // Backend type, e.g. "github"
+	AuthMode string `json:"authMode,omitempty"` // How requests to the backend are authenticated
+	Strict   bool   `json:"strict,omitempty"`   // Whether broken credentials fail requests instead of degrading
+	Healthy  bool   `json:"healthy"`
+	Error    string `json:"error,omitempty"` // Last credential failure when unhealthy
+}
+
+// StatusReporter is implemented by sources that report the health of their configuration
+type StatusReporter interface {
+	Status() Status
+}
+
+// Verifier is implemented by sources that can check their credentials against the backend at startup
+type Verifier interface {
+	// Verify returns an error when the credentials are broken and the source is configured to refuse starting.
+	// Failures of sources that degrade instead are only reflected in their Status.
+	Verify(ctx context.Context) error
+}
@@ -0,0 +1,93 @@
+package sources
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version parsed from a tag name
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string // Dot separated pre-release identifiers, e.g. "rc.1"
+}
+
+// ParseVersion parses a tag name as a semantic version once the longest of prefixes it starts with is removed.
+// Build metadata is ignored, and a missing patch number defaults to zero, so "v1.2" is 1.2.0.
+func ParseVersion(name string, prefixes []string) (Version, bool) {
+	longest := ""
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(longest) {
+			longest = prefix
+		}
+	}
+	name = strings.TrimPrefix(name, longest)
+	name, _, _ = strings.Cut(name, "+")
+	name, prerelease, _ := strings.Cut(name, "-")
+
+	parts := strings.Split(name, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, false
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return Version{}, false
+		}
+		numbers[i] = n
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Prerelease: prerelease}, true
+}
+
+// Compare returns -1, 0 or 1 as v has lower, equal or higher precedence than other
+func (v Version) Compare(other Version) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff != 0 {
+			return sign(diff)
+		}
+	}
+
+	// A pre-release has lower precedence than the release itself
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+
+	ids, otherIDs := strings.Split(v.Prerelease, "."), strings.Split(other.Prerelease, ".")
+	for i := 0; i < len(ids) && i < len(otherIDs); i++ {
+		if c := compareIdentifiers(ids[i], otherIDs[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(ids) - len(otherIDs))
+}
+
+// compareIdentifiers compares pre-release identifiers, numerically when both are numbers
+func compareIdentifiers(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(an - bn)
+	case aErr == nil:
+		return -1 // Numeric identifiers sort before alphanumeric ones
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
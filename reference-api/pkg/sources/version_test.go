@@ -0,0 +1,65 @@
+package sources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		prefixes []string
+		want     Version
+		wantOK   bool
+	}{
+		{name: "Plain", tag: "1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}, wantOK: true},
+		{name: "v prefix", tag: "v1.2.3", prefixes: []string{"v"}, want: Version{Major: 1, Minor: 2, Patch: 3}, wantOK: true},
+		{name: "Missing patch", tag: "v2.0", prefixes: []string{"v"}, want: Version{Major: 2}, wantOK: true},
+		{
+			name:     "Pre-release and build metadata",
+			tag:      "v1.0.0-rc.1+build.5",
+			prefixes: []string{"v"},
+			want:     Version{Major: 1, Prerelease: "rc.1"},
+			wantOK:   true,
+		},
+		{
+			name:     "Longest prefix wins",
+			tag:      "app-name/v3.1.4",
+			prefixes: []string{"v", "app-name/", "app-name/v"},
+			want:     Version{Major: 3, Minor: 1, Patch: 4},
+			wantOK:   true,
+		},
+		{name: "Unknown prefix", tag: "other/v1.0.0", prefixes: []string{"v"}},
+		{name: "Prefix not configured", tag: "v1.0.0"},
+		{name: "Single number", tag: "2024"},
+		{name: "Too many parts", tag: "1.2.3.4"},
+		{name: "Leading zero", tag: "1.02.3"},
+		{name: "Not a version", tag: "release-candidate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseVersion(tt.tag, tt.prefixes)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// Ordered by increasing precedence
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11",
+		"1.0.0-rc.1", "1.0.0", "1.4.9", "1.10.0", "2.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := ParseVersion(ordered[i], nil)
+			b, _ := ParseVersion(ordered[j], nil)
+			assert.Equal(t, sign(i-j), a.Compare(b), "%s <=> %s", ordered[i], ordered[j])
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// ReleasesHandler lists the releases between the deployed gitRef and the latest reference, with their notes
+func (deps *HandlerDeps) ReleasesHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := deps.parseReferenceRequest(w, r)
+	if !ok {
+		return
+	}
+
+	source, repoPath, ok := deps.lookupSource(w, r, req)
+	if !ok {
+		return
+	}
+
+	cacheKey := "releases:" + req.cacheKey()
+	if cachedResponse, ok := deps.getFromCache(cacheKey); ok {
+		w.WriteHeader(cachedResponse.StatusCode)
+		_, _ = w.Write(cachedResponse.Body)
+		return
+	}
+
+	var pending *sources.PendingReleases
+	output, err := deps.references(r.Context(), source, repoPath, req)
+	if err == nil {
+		pending, err = sources.ReleasesBetween(r.Context(), source, sources.Query{
+			Repo:   repoPath,
+			Latest: deps.Config.latestPolicy(req.repo),
+			Scope:  req.scope,
+		}, output)
+	}
+
+	statusCode, body := encodeResult(pending, err)
+	writeResult(w, statusCode, body)
+	deps.storeInCache(cacheKey, statusCode, body)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/stretchr/testify/assert"
+)
+
+// releasedSource is a mockSource whose releases are v1.0.0 to v1.3.0, v1.2.0 being a prerelease
+type releasedSource struct {
+	mockSource
+}
+
+func (s *releasedSource) ResolveLatest(ctx context.Context, q sources.Query) (*sources.StandardizedEntity, error) {
+	return &sources.StandardizedEntity{Ref: "v1.3.0"}, nil
+}
+
+func (s *releasedSource) ListReleases(ctx context.Context, repo string) ([]*sources.StandardizedEntity, error) {
+	return []*sources.StandardizedEntity{
+		{Ref: "v1.3.0", Message: "Third"},
+		{Ref: "v1.2.0", Message: "Candidate", Prerelease: true},
+		{Ref: "v1.1.0", Message: "Second"},
+		{Ref: "v1.0.0", Message: "First"},
+	}, nil
+}
+
+func TestReleasesHandler(t *testing.T) {
+	found := func(sources.Kind, string) bool { return true }
+
+	deps := newTestDeps(t, &releasedSource{mockSource{found: found}})
+	rr := serveLookup(deps, deps.ReleasesHandler, "/api/references/releases", url.Values{})
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var pending sources.PendingReleases
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &pending))
+	assert.Equal(t, "v1.0.0", pending.Current.Ref)
+	assert.Equal(t, "v1.3.0", pending.Latest.Ref)
+	assert.Equal(t, []*sources.StandardizedEntity{{Ref: "v1.1.0", Message: "Second"}}, pending.Releases)
+
+	deps = newTestDeps(t, &mockSource{found: found})
+	rr = serveLookup(deps, deps.ReleasesHandler, "/api/references/releases", url.Values{})
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+	assert.Equal(t, `{"error":"unsupported by source: the source has no releases"}`+"\n", rr.Body.String())
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+)
+
+// defaultRoute names the source serving repositories that are not routed elsewhere
+const defaultRoute = "default"
+
+// StatusResponse describes the configured sources and whether their credentials work
+type StatusResponse struct {
+	Healthy bool           `json:"healthy"`
+	Sources []SourceStatus `json:"sources"`
+}
+
+// SourceStatus is the status of the source serving the repositories under Route
+type SourceStatus struct {
+	Route string `json:"route"`
+	sources.Status
+}
+
+// sortedRoutes returns the sources of the registry ordered by route, with the default source first
+func sortedRoutes(registry *sources.Registry) ([]string, map[string]sources.Source) {
+	all := registry.Sources()
+	routes := make([]string, 0, len(all))
+	for route := range all {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes, all
+}
+
+// StatusHandler reports the auth mode and health of every source, answering 503 when any is unhealthy
+func (deps *HandlerDeps) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	response := StatusResponse{Healthy: true, Sources: []SourceStatus{}}
+
+	routes, all := sortedRoutes(deps.Sources)
+	for _, route := range routes {
+		status := sources.Status{Healthy: true}
+		if reporter, ok := all[route].(sources.StatusReporter); ok {
+			status = reporter.Status()
+		}
+		if route == "" {
+			route = defaultRoute
+		}
+		response.Sources = append(response.Sources, SourceStatus{Route: route, Status: status})
+		response.Healthy = response.Healthy && status.Healthy
+	}
+
+	statusCode := http.StatusOK
+	if !response.Healthy {
+		statusCode = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// verifySources checks the credentials of every source that supports it.
+// Only sources configured to refuse broken credentials fail the check.
+func verifySources(ctx context.Context, registry *sources.Registry) error {
+	routes, all := sortedRoutes(registry)
+	for _, route := range routes {
+		verifier, ok := all[route].(sources.Verifier)
+		if !ok {
+			continue
+		}
+		if err := verifier.Verify(ctx); err != nil {
+			if route == "" {
+				route = defaultRoute
+			}
+			return fmt.Errorf("source %s: %v", route, err)
+		}
+	}
+	return nil
+}
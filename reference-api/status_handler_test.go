@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
+	"github.com/stretchr/testify/assert"
+)
+
+// statusSource is a mockSource reporting a fixed status and verification result
+type statusSource struct {
+	mockSource
+	status    sources.Status
+	verifyErr error
+}
+
+func (s *statusSource) Status() sources.Status { return s.status }
+
+func (s *statusSource) Verify(ctx context.Context) error { return s.verifyErr }
+
+func TestStatusHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		ghe            sources.Status
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Healthy",
+			ghe:            sources.Status{Type: "github", AuthMode: "app", Strict: true, Healthy: true},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"healthy":true,"sources":[` +
+				`{"route":"default","healthy":true},` +
+				`{"route":"ghe.example.com","type":"github","authMode":"app","strict":true,"healthy":true}]}`,
+		},
+		{
+			name:           "Unhealthy",
+			ghe:            sources.Status{Type: "github", AuthMode: "token", Error: "bad credentials"},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: `{"healthy":false,"sources":[` +
+				`{"route":"default","healthy":true},` +
+				`{"route":"ghe.example.com","type":"github","authMode":"token","healthy":false,"error":"bad credentials"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newMockRegistry(func(sources.Kind, string) bool { return true })
+			registry.RegisterHost("ghe.example.com", &statusSource{status: tt.ghe})
+			deps := &HandlerDeps{Sources: registry}
+
+			rr := httptest.NewRecorder()
+			deps.StatusHandler(rr, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestVerifySources(t *testing.T) {
+	registry := newMockRegistry(func(sources.Kind, string) bool { return true })
+	registry.RegisterHost("ghe.example.com", &statusSource{})
+	assert.NoError(t, verifySources(context.Background(), registry))
+
+	registry.RegisterHost("broken.example.com", &statusSource{verifyErr: errors.New("bad credentials")})
+	assert.EqualError(t, verifySources(context.Background(), registry), "source broken.example.com: bad credentials")
+}
@@ -2,34 +2,40 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"net/http/httptest"
 	"strings"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/oci"
+	"github.com/mozilla/argocd-repository-details/reference-api/pkg/sources"
 )
 
 type HandlerDeps struct {
-	CommitsHandler  http.HandlerFunc
-	ReleasesHandler http.HandlerFunc
-	TagsHandler     http.HandlerFunc
-	cache           *lru.Cache[string, CachedResponse]
-	config          cacheConfiguration
+	Sources       *sources.Registry
+	Images        *oci.Client // Reads the labels of images, may be nil when image lookups are not configured
+	Config        *Config     // Per repository settings, may be nil
+	DefaultBranch string      // Branch tracked by commit deployments when none is configured. Empty for the default branch
+	cache         *lru.Cache[string, CachedResponse]
+	config        cacheConfiguration
 }
 
 type CachedResponse struct {
 	StatusCode int
 	Body       []byte
-	Timestamp  int64 // Unix timestamp when stored
+	Timestamp  int64         // Unix timestamp when stored
+	TTL        time.Duration // Overrides the duration of the status code when set
 }
 
-type responseRecorder struct {
-	http.ResponseWriter
-	statusCode int
-	body       bytes.Buffer
+// ErrorResponse represents an error message
+type ErrorResponse struct {
+	Error      string   `json:"error"`
+	Candidates []string `json:"candidates,omitempty"` // Commits an ambiguous abbreviated SHA matches
 }
 
 type cacheConfiguration struct {
@@ -37,41 +43,141 @@ type cacheConfiguration struct {
 	ErrorCacheDuration   time.Duration
 }
 
-func (rec *responseRecorder) Write(p []byte) (int, error) {
-	if rec.statusCode == 0 { // Default to 200 if WriteHeader was never called
-		rec.statusCode = http.StatusOK
-	}
-	rec.body.Write(p)
-	return rec.ResponseWriter.Write(p)
-}
+// referenceRequest is the lookup of a gitRef described by the query parameters of a request
+type referenceRequest struct {
+	repo        string
+	gitRef      string // gitRef without its image tag metadata
+	fallbackSHA string // Commit resolved when gitRef is not found, as extracted alongside it from the image tag
+	scope       sources.TagScope
+	branch      string
+	order       sources.ResolutionOrder // Order requested by the kind query parameter, nil for the configured one
 
-func (rec *responseRecorder) WriteHeader(code int) {
-	rec.statusCode = code
-	rec.ResponseWriter.WriteHeader(code)
+	extraction *sources.Extraction // Configured rule the gitRef was extracted by, nil for the default convention
 }
 
-// UnifiedHandler with in-memory caching
-func (deps *HandlerDeps) UnifiedHandler(w http.ResponseWriter, r *http.Request) {
+// parseReferenceRequest reads the lookup requested by r, answering with an error when it is invalid
+func (deps *HandlerDeps) parseReferenceRequest(w http.ResponseWriter, r *http.Request) (*referenceRequest, bool) {
 	repo := r.URL.Query().Get("repo")
 	gitRef := r.URL.Query().Get("gitRef")
 
+	// Images tell the repository and commit they were built from by their labels, unless given explicitly
+	image := r.URL.Query().Get("image")
+	fromLabels := false
+	if image != "" && (repo == "" || gitRef == "") {
+		built, err := deps.lookupImage(r.Context(), image)
+		if err != nil {
+			statusCode, body := encodeResult[sources.StandardizedOutput](nil, err)
+			writeResult(w, statusCode, body)
+			return nil, false
+		}
+		if repo == "" {
+			repo = built.Repo
+		}
+		if gitRef == "" {
+			gitRef, fromLabels = built.Revision, true
+		}
+		if repo == "" || gitRef == "" {
+			http.Error(w, fmt.Sprintf("Image '%s' lacks the '%s' or '%s' label", image, oci.LabelSource, oci.LabelRevision),
+				http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
 	if repo == "" || gitRef == "" {
 		http.Error(w, "Missing 'repo' or 'gitRef' query parameter", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	if gitRef == "latest" {
 		http.Error(w, "'latest' is not a valid value for 'gitRef'. Please use an immutable image.", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
-	// Strip optional --<metadata> suffix from image tags
+	// Extract the gitRef with the first configured rule matching the image tag
+	//  "1.4.2-abcdef0" → "1.4.2", falling back to commit "abcdef0"
+	// else strip the optional --<metadata> suffix
 	//  "v1.2.3--release" → "v1.2.3"
 	//  "dd295fd679--stage" → "dd295fd679"
-	baseGitRef, _, _ := strings.Cut(gitRef, "--")
+	// Revisions read from image labels are used as they are
+	var fallbackSHA string
+	var extraction *sources.Extraction
+	if !fromLabels {
+		var ok bool
+		if extraction, ok = sources.ExtractRef(deps.Config.refRules(repo), gitRef); ok {
+			gitRef, fallbackSHA = extraction.GitRef(), extraction.FallbackSHA()
+		} else {
+			gitRef, _, _ = strings.Cut(gitRef, "--")
+		}
+	}
 
+	// Scope tags to the release stream of the application, which query parameters override
+	pattern, err := sources.ParsePattern(r.URL.Query().Get("tagPattern"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'tagPattern' query parameter: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+	scope := deps.Config.tagScope(r.Header.Get("Argocd-Application-Name")).Override(sources.TagScope{
+		Prefix:  r.URL.Query().Get("tagPrefix"),
+		Pattern: pattern,
+	})
+
+	// Try the kinds of reference requested, such as "commit" or "tag,commit", instead of the configured ones
+	var order sources.ResolutionOrder
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		var err error
+		if order, err = sources.ParseResolutionOrder(kind); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid 'kind' query parameter: %v", err), http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
+	// Track the branch requested, else the one configured for the repository, else the default one
+	branch := r.URL.Query().Get("branch")
+	if branch == "" {
+		branch = deps.Config.branch(repo)
+	}
+	if branch == "" {
+		branch = deps.DefaultBranch
+	}
+
+	return &referenceRequest{
+		repo:        repo,
+		gitRef:      gitRef,
+		fallbackSHA: fallbackSHA,
+		scope:       scope,
+		branch:      branch,
+		order:       order,
+		extraction:  extraction,
+	}, true
+}
+
+// cacheKey returns the key the response to the lookup is cached under
+func (req *referenceRequest) cacheKey() string {
 	// Use base gitRef for cache key (tags with different metadata share the same cache entry)
-	cacheKey := fmt.Sprintf("%s:%s", repo, baseGitRef)
+	cacheKey := fmt.Sprintf("%s:%s", req.repo, req.gitRef)
+	if req.extraction != nil {
+		cacheKey += fmt.Sprintf(":%s:%s", req.extraction.Rule, req.fallbackSHA)
+	}
+	if req.scope != (sources.TagScope{}) || req.branch != "" {
+		cacheKey += fmt.Sprintf(":%s:%s:%s", req.scope.Prefix, req.scope.Pattern.String(), req.branch)
+	}
+	if req.order != nil {
+		cacheKey += ":kind=" + req.order.String()
+	}
+	return cacheKey
+}
+
+// UnifiedHandler with in-memory caching
+func (deps *HandlerDeps) UnifiedHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := deps.parseReferenceRequest(w, r)
+	if !ok {
+		return
+	}
+	source, repoPath, ok := deps.lookupSource(w, r, req)
+	if !ok {
+		return
+	}
+	cacheKey := req.cacheKey()
 
 	// Check cache first
 	if cachedResponse, ok := deps.getFromCache(cacheKey); ok {
@@ -80,64 +186,165 @@ func (deps *HandlerDeps) UnifiedHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Modify request to use base gitRef (without metadata) for GitHub queries
-	q := r.URL.Query()
-	q.Set("gitRef", baseGitRef)
-	r.URL.RawQuery = q.Encode()
+	statusCode, body := encodeResult(deps.resolve(r.Context(), source, repoPath, req))
+	writeResult(w, statusCode, body)
+	deps.storeInCache(cacheKey, statusCode, body)
+}
 
-	// Try handling as a release first
-	releaseRecorder := &responseRecorder{
-		ResponseWriter: httptest.NewRecorder(),
-		statusCode:     0,
+// lookupSource finds the source of the repository of the lookup, answering with an error when there is none.
+// Abbreviated SHAs tried as commits first are expanded, so that every abbreviation of a commit and its full SHA share
+// the cached responses keyed by the lookup.
+func (deps *HandlerDeps) lookupSource(
+	w http.ResponseWriter, r *http.Request, req *referenceRequest,
+) (sources.Source, string, bool) {
+	source, repoPath := deps.Sources.Lookup(req.repo)
+	if source == nil {
+		http.Error(w, fmt.Sprintf("No source configured for repository '%s'", req.repo), http.StatusBadRequest)
+		return nil, "", false
 	}
-	deps.ReleasesHandler(releaseRecorder, r)
 
-	// If release is found (not 404), cache and return
-	if releaseRecorder.statusCode != http.StatusNotFound {
-		w.WriteHeader(releaseRecorder.statusCode)
-		if _, err := w.Write(releaseRecorder.body.Bytes()); err != nil {
-			log.Printf("Error writing response: %v", err)
+	if order := deps.resolutionOrder(req); len(order) > 0 && order[0] == sources.KindCommit {
+		sha, err := deps.expandCommitSHA(r.Context(), source, repoPath, req, req.gitRef)
+		if err != nil {
+			statusCode, body := encodeResult[sources.StandardizedOutput](nil, err)
+			writeResult(w, statusCode, body)
+			return nil, "", false
 		}
-		deps.storeInCache(cacheKey, releaseRecorder.statusCode, releaseRecorder.body.Bytes())
-		return
+		req.gitRef = sha
+	}
+	return source, repoPath, true
+}
+
+// expandCommitSHA returns the full SHA of the commit gitRef abbreviates, or gitRef unchanged when it is not an
+// abbreviated SHA or cannot be expanded. It is only called once gitRef is tried as a commit, so that tags named like
+// SHAs cost no expansion and are not replaced by the commit they happen to prefix.
+func (deps *HandlerDeps) expandCommitSHA(
+	ctx context.Context, source sources.Source, repoPath string, req *referenceRequest, gitRef string,
+) (string, error) {
+	if !sources.IsAbbreviatedSHA(gitRef) {
+		return gitRef, nil
+	}
+
+	// Commits never change SHA, so expansions are cached like successful lookups
+	expansionKey := fmt.Sprintf("sha:%s:%s", req.repo, strings.ToLower(gitRef))
+	if cachedResponse, ok := deps.getFromCache(expansionKey); ok && cachedResponse.StatusCode == http.StatusOK {
+		return string(cachedResponse.Body), nil
+	}
+
+	sha, err := sources.ExpandCommitSHA(ctx, source, repoPath, gitRef)
+	switch {
+	case errors.Is(err, sources.ErrAmbiguousRef):
+		return "", err
+	case err != nil:
+		// Look the abbreviated SHA up as is, as before expansion
+		log.Printf("Error expanding commit SHA %s of %s: %v", gitRef, req.repo, err)
+		return gitRef, nil
+	}
+	if sha != gitRef {
+		deps.storeInCache(expansionKey, http.StatusOK, []byte(sha))
+	}
+	return sha, nil
+}
+
+// resolutionOrder returns the order the gitRef of the lookup is tried in: the requested one, else the one configured
+// for the repository
+func (deps *HandlerDeps) resolutionOrder(req *referenceRequest) sources.ResolutionOrder {
+	if req.order != nil {
+		return req.order
 	}
+	return deps.Config.resolutionOrder(req.repo).For(req.gitRef)
+}
 
-	// Try handling as a tag second
-	tagRecorder := &responseRecorder{
-		ResponseWriter: httptest.NewRecorder(),
-		statusCode:     0,
+// resolve looks up the gitRef as each kind of reference of the resolution order in turn, falling through to the next one
+// only when not found, and then to the commit extracted alongside it from the image tag.
+// Obvious commit SHAs are tried as commits first, unless the order was requested explicitly, and abbreviated SHAs are
+// expanded when tried as commits.
+func (deps *HandlerDeps) resolve(
+	ctx context.Context, source sources.Source, repoPath string, req *referenceRequest,
+) (*sources.StandardizedOutput, error) {
+	query := sources.Query{
+		Repo:   repoPath,
+		Latest: deps.Config.latestPolicy(req.repo),
+		Scope:  req.scope,
+		Branch: req.branch,
+
+		RequireSignature: deps.Config.requireSignatures(req.repo),
 	}
-	deps.TagsHandler(tagRecorder, r)
 
-	// If tag is found (not 404), cache and return
-	if tagRecorder.statusCode != http.StatusNotFound {
-		w.WriteHeader(tagRecorder.statusCode)
-		if _, err := w.Write(tagRecorder.body.Bytes()); err != nil {
-			log.Printf("Error writing response: %v", err)
+	var output *sources.StandardizedOutput
+	var err error
+	for _, kind := range deps.resolutionOrder(req) {
+		query.GitRef, query.Kind = req.gitRef, kind
+		if kind == sources.KindCommit {
+			if query.GitRef, err = deps.expandCommitSHA(ctx, source, repoPath, req, req.gitRef); err != nil {
+				return nil, err
+			}
 		}
-		deps.storeInCache(cacheKey, tagRecorder.statusCode, tagRecorder.body.Bytes())
-		return
+		output, err = sources.Resolve(ctx, source, query)
+		if !errors.Is(err, sources.ErrNotFound) {
+			break
+		}
+	}
+	if errors.Is(err, sources.ErrNotFound) && req.fallbackSHA != "" {
+		query.Kind = sources.KindCommit
+		if query.GitRef, err = deps.expandCommitSHA(ctx, source, repoPath, req, req.fallbackSHA); err != nil {
+			return nil, err
+		}
+		output, err = sources.Resolve(ctx, source, query)
 	}
 
-	// Clear previous response before falling back
-	w.Header().Del("Content-Type") // Ensure correct content type is set by CommitsHandler
-	w.WriteHeader(http.StatusOK)   // Reset status before falling back
+	if output != nil {
+		output.Extraction = req.extraction
+	}
+	return output, err
+}
 
-	// Fall back to CommitsHandler
-	deps.processAndCacheResponse(deps.CommitsHandler, w, r, cacheKey)
+// writeResult writes an encoded JSON result
+func writeResult(w http.ResponseWriter, statusCode int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
 }
 
-func (deps *HandlerDeps) processAndCacheResponse(
-	handler http.HandlerFunc,
-	w http.ResponseWriter,
-	r *http.Request,
-	cacheKey string,
-) {
-	rec := &responseRecorder{ResponseWriter: w, statusCode: 0} // Use 0 so that we can catch missing status updates
-	handler(rec, r)
+// encodeResult converts the outcome of a lookup into a status code and JSON body
+func encodeResult[T any](output *T, err error) (int, []byte) {
+	statusCode := http.StatusOK
+	var body any = output
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sources.ErrNotFound):
+			statusCode = http.StatusNotFound
+			body = ErrorResponse{Error: err.Error()}
+		case errors.Is(err, sources.ErrInvalidRef):
+			statusCode = http.StatusBadRequest
+			body = ErrorResponse{Error: err.Error()}
+		case errors.Is(err, sources.ErrUnsupported):
+			statusCode = http.StatusNotImplemented
+			body = ErrorResponse{Error: err.Error()}
+		case errors.Is(err, sources.ErrAmbiguousRef):
+			statusCode = http.StatusConflict
+			response := ErrorResponse{Error: err.Error()}
+			var ambiguous *sources.AmbiguousRefError
+			if errors.As(err, &ambiguous) {
+				response.Candidates = ambiguous.Candidates
+			}
+			body = response
+		default:
+			log.Printf("Error fetching reference information: %v", err)
+			statusCode = http.StatusInternalServerError
+			body = ErrorResponse{Error: "Failed to fetch reference information"}
+		}
+	}
 
-	// Store both status and response body
-	deps.storeInCache(cacheKey, rec.statusCode, rec.body.Bytes())
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		log.Printf("Error encoding response: %v", err)
+		return http.StatusInternalServerError, []byte(`{"error":"Internal Server Error"}` + "\n")
+	}
+	return statusCode, buf.Bytes()
 }
 
 func (deps *HandlerDeps) getFromCache(key string) (CachedResponse, bool) {
@@ -146,7 +353,9 @@ func (deps *HandlerDeps) getFromCache(key string) (CachedResponse, bool) {
 
 		// Determine expiration time based on status code
 		var expirationTime time.Time
-		if value.StatusCode == http.StatusOK {
+		if value.TTL > 0 {
+			expirationTime = time.Unix(value.Timestamp, 0).Add(value.TTL)
+		} else if value.StatusCode == http.StatusOK {
 			expirationTime = time.Unix(value.Timestamp, 0).Add(deps.config.SuccessCacheDuration)
 		} else {
 			expirationTime = time.Unix(value.Timestamp, 0).Add(deps.config.ErrorCacheDuration)
@@ -170,12 +379,18 @@ func (deps *HandlerDeps) getFromCache(key string) (CachedResponse, bool) {
 }
 
 func (deps *HandlerDeps) storeInCache(key string, statusCode int, value []byte) {
+	deps.storeInCacheFor(key, statusCode, value, 0)
+}
+
+// storeInCacheFor caches the response for ttl, or for the duration of its status code when ttl is zero
+func (deps *HandlerDeps) storeInCacheFor(key string, statusCode int, value []byte, ttl time.Duration) {
 	timestamp := time.Now().Unix() // Store current timestamp
 
 	deps.cache.Add(key, CachedResponse{
 		StatusCode: statusCode,
 		Body:       value,
 		Timestamp:  timestamp,
+		TTL:        ttl,
 	})
 
 	log.Printf("Cached response for key: %s, Status: %d (Stored at %d)", key, statusCode, timestamp)
@@ -3,7 +3,7 @@ import { useState, useEffect } from "react";
 import ReactMarkdown from "react-markdown";
 import { getHeaders } from "../shared/headers";
 import { getAppDetails } from "../shared/parse-app-info";
-import { ReleaseInfo } from "../shared/release-info";
+import { PendingReleases, ReleaseInfo } from "../shared/release-info";
 
 
 interface ReleaseDetailsPanelFlyoutProps {
@@ -14,6 +14,7 @@ export const ReleaseDetailsPanel = ({ application }: ReleaseDetailsPanelFlyoutPr
   const [releaseInfo, setReleaseInfo] = useState<ReleaseInfo | null>(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState(null);
+  const [pendingReleases, setPendingReleases] = useState<PendingReleases | null>(null);
 
   const applicationNamespace = application?.metadata?.namespace || "";
   const applicationName = application?.metadata?.name || "";
@@ -25,9 +26,9 @@ export const ReleaseDetailsPanel = ({ application }: ReleaseDetailsPanelFlyoutPr
     if (!application) return;
 
     // We are checking the application repository for a gitRef that matches the imageTag
-    const { appRepository, imageTag } = getAppDetails(images, info);
+    const { appRepository, imageTag, referenceParams } = getAppDetails(images, info);
 
-    const cacheKey = `${appRepository}-${imageTag}`;
+    const cacheKey = `${appRepository}-${imageTag}${referenceParams}`;
     const cachedData = sessionStorage.getItem(cacheKey);
 
     const fetchReleaseInfo = async () => {
@@ -40,7 +41,7 @@ export const ReleaseDetailsPanel = ({ application }: ReleaseDetailsPanelFlyoutPr
         }
 
         const response = await fetch(
-          `/extensions/repository-details/api/references?repo=${appRepository}&gitRef=${imageTag}`,
+          `/extensions/repository-details/api/references?repo=${appRepository}&gitRef=${imageTag}${referenceParams}`,
           { headers: getHeaders({ applicationName, applicationNamespace, project }) }
         );
 
@@ -60,7 +61,35 @@ export const ReleaseDetailsPanel = ({ application }: ReleaseDetailsPanelFlyoutPr
       }
     };
 
+    // Releases between the deployed and latest ones make up the pending changelog
+    const fetchPendingReleases = async () => {
+      const releasesCacheKey = `releases-${cacheKey}`;
+      const cachedReleases = sessionStorage.getItem(releasesCacheKey);
+      if (cachedReleases) {
+        setPendingReleases(JSON.parse(cachedReleases));
+        return;
+      }
+
+      try {
+        const response = await fetch(
+          `/extensions/repository-details/api/references/releases?repo=${appRepository}&gitRef=${imageTag}${referenceParams}`,
+          { headers: getHeaders({ applicationName, applicationNamespace, project }) }
+        );
+        // Sources without releases have no changelog to show
+        if (!response.ok) {
+          return;
+        }
+
+        const data = await response.json();
+        sessionStorage.setItem(releasesCacheKey, JSON.stringify(data));
+        setPendingReleases(data);
+      } catch (err) {
+        console.info("Pending releases unavailable: ", err.message);
+      }
+    };
+
     fetchReleaseInfo();
+    fetchPendingReleases();
   }, [application]);
 
   if (loading) {
@@ -138,6 +167,59 @@ export const ReleaseDetailsPanel = ({ application }: ReleaseDetailsPanelFlyoutPr
               <div className="columns small-3">AUTHOR</div>
               <div className="columns small-9">{releaseInfo.current?.author || "Unknown"}</div>
             </div>
+            {releaseInfo.current?.tag_kind && (
+              <div className="row white-box__details-row">
+                <div className="columns small-3">TAG</div>
+                <div className="columns small-9">{releaseInfo.current.tag_kind === "annotated" ? "Annotated" : "Lightweight"}</div>
+              </div>
+            )}
+            {releaseInfo.current?.verification && (
+              <div className="row white-box__details-row">
+                <div className="columns small-3">SIGNATURE</div>
+                <div className="columns small-9">
+                  {releaseInfo.current.verification.verified
+                    ? `Verified, signed by ${releaseInfo.current.verification.signer || "Unknown"}`
+                    : `Not verified (${releaseInfo.current.verification.reason || "unknown"})`}
+                </div>
+              </div>
+            )}
+            {releaseInfo.current?.checks && (
+              <div className="row white-box__details-row">
+                <div className="columns small-3">CI CHECKS</div>
+                <div className="columns small-9">
+                  {`${releaseInfo.current.checks.state}: ${releaseInfo.current.checks.success} passed, ${releaseInfo.current.checks.failure} failed, ${releaseInfo.current.checks.pending} pending`}
+                  {releaseInfo.current.checks.checks?.filter((check) => check.state !== "success").map((check) => (
+                    <div key={check.name}>
+                      <a href={check.url} target="_blank" rel="noopener noreferrer" style={{ textDecoration: "none", color: "#007bff" }}>
+                        {check.name}
+                      </a>
+                      {` ${check.state}`}
+                    </div>
+                  ))}
+                </div>
+              </div>
+            )}
+            {releaseInfo.current?.pull_requests?.length > 0 && (
+              <div className="row white-box__details-row">
+                <div className="columns small-3">PULL REQUESTS</div>
+                <div className="columns small-9">
+                  {releaseInfo.current.pull_requests.map((pull) => (
+                    <div key={pull.number}>
+                      <a
+                        href={pull.url}
+                        target="_blank"
+                        rel="noopener noreferrer"
+                        style={{ textDecoration: "none", color: "#007bff" }}
+                      >
+                        #{pull.number} {pull.title}
+                      </a>
+                      {` by ${pull.author || "Unknown"}`}
+                      {pull.merged_at ? `, merged ${pull.merged_at}` : ""}
+                    </div>
+                  ))}
+                </div>
+              </div>
+            )}
           </div>
         </div>
       </div>
@@ -203,9 +285,87 @@ export const ReleaseDetailsPanel = ({ application }: ReleaseDetailsPanelFlyoutPr
               <div className="columns small-3">AUTHOR</div>
               <div className="columns small-9">{releaseInfo.latest?.author || "Unknown"}</div>
             </div>
+            {releaseInfo.latest?.tag_kind && (
+              <div className="row white-box__details-row">
+                <div className="columns small-3">TAG</div>
+                <div className="columns small-9">{releaseInfo.latest.tag_kind === "annotated" ? "Annotated" : "Lightweight"}</div>
+              </div>
+            )}
+            {releaseInfo.latest?.verification && (
+              <div className="row white-box__details-row">
+                <div className="columns small-3">SIGNATURE</div>
+                <div className="columns small-9">
+                  {releaseInfo.latest.verification.verified
+                    ? `Verified, signed by ${releaseInfo.latest.verification.signer || "Unknown"}`
+                    : `Not verified (${releaseInfo.latest.verification.reason || "unknown"})`}
+                </div>
+              </div>
+            )}
+            {releaseInfo.latest?.checks && (
+              <div className="row white-box__details-row">
+                <div className="columns small-3">CI CHECKS</div>
+                <div className="columns small-9">
+                  {`${releaseInfo.latest.checks.state}: ${releaseInfo.latest.checks.success} passed, ${releaseInfo.latest.checks.failure} failed, ${releaseInfo.latest.checks.pending} pending`}
+                  {releaseInfo.latest.checks.checks?.filter((check) => check.state !== "success").map((check) => (
+                    <div key={check.name}>
+                      <a href={check.url} target="_blank" rel="noopener noreferrer" style={{ textDecoration: "none", color: "#007bff" }}>
+                        {check.name}
+                      </a>
+                      {` ${check.state}`}
+                    </div>
+                  ))}
+                </div>
+              </div>
+            )}
           </div>
         </div>
       </div>
+
+      {/* Releases pending between the deployed and latest ones */}
+      {pendingReleases?.releases?.length > 0 && (
+        <div className="columns small-12">
+          <div className="white-box">
+            <div className="white-box__details">
+              <p>PENDING RELEASES</p>
+              {pendingReleases.releases.map((release) => (
+                <div key={release.ref} className="row white-box__details-row">
+                  <div className="columns small-3">
+                    {release.url ? (
+                      <a
+                        href={release.url}
+                        target="_blank"
+                        rel="noopener noreferrer"
+                        style={{ textDecoration: "none", color: "#007bff" }}
+                      >
+                        {release.ref}
+                      </a>
+                    ) : (
+                      release.ref
+                    )}
+                    <div>{release.published_at || ""}</div>
+                  </div>
+                  <div className="columns small-9">
+                    {release.message ? (
+                      <ReactMarkdown
+                        components={{
+                          h1: ({ children }) => <h3>{children}</h3>, // Shrink h1 to h3
+                          h2: ({ children }) => <h4>{children}</h4>, // Shrink h2 to h4
+                          h3: ({ children }) => <h5>{children}</h5>, // Shrink h3 to h5
+                          h4: ({ children }) => <h6>{children}</h6>, // Shrink h4 to h6
+                        }}
+                      >
+                        {release.message}
+                      </ReactMarkdown>
+                    ) : (
+                      "No description available"
+                    )}
+                  </div>
+                </div>
+              ))}
+            </div>
+          </div>
+        </div>
+      )}
     </div>
   );
 };
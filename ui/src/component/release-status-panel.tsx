@@ -9,6 +9,12 @@ import { getAppDetails } from "../shared/parse-app-info";
 import { ReleaseInfo } from "../shared/release-info";
 
 
+// Colors of the CI states of the deployed commit
+const checkColors = {
+  success: "#18be94",
+  failure: "#e96d76",
+  pending: "#0dadea",
+};
 
 export const ReleaseStatusPanel = ({ application, openFlyout }) => {
   const [releaseInfo, setReleaseInfo] = useState<ReleaseInfo | null>(null);
@@ -23,7 +29,7 @@ export const ReleaseStatusPanel = ({ application, openFlyout }) => {
 
   useEffect(() => {
     // We are checking the application repository for a gitRef that matches the imageTag
-    const { appRepository, imageTag } = getAppDetails(images, info);
+    const { appRepository, imageTag, referenceParams } = getAppDetails(images, info);
 
     // Check for missing appRepository or imageTag
     if (!appRepository || !imageTag) {
@@ -35,7 +41,7 @@ export const ReleaseStatusPanel = ({ application, openFlyout }) => {
     }
 
     const fetchReleaseInfo = async () => {
-      const cacheKey = `${appRepository}-${imageTag}`;
+      const cacheKey = `${appRepository}-${imageTag}${referenceParams}`;
       const cachedData = sessionStorage.getItem(cacheKey);
 
       if (cachedData) {
@@ -47,7 +53,7 @@ export const ReleaseStatusPanel = ({ application, openFlyout }) => {
 
       try {
         const response = await fetch(
-          `/extensions/repository-details/api/references?repo=${appRepository}&gitRef=${imageTag}`,
+          `/extensions/repository-details/api/references?repo=${appRepository}&gitRef=${imageTag}${referenceParams}`,
           { headers: getHeaders({ applicationName, applicationNamespace, project }) }
         );
         if (!response.ok) {
@@ -181,8 +187,40 @@ export const ReleaseStatusPanel = ({ application, openFlyout }) => {
                   : "N/A"
               }
             </span>
+            {/* CI state of the deployed commit */}
+            {releaseInfo.current?.checks && (
+              <span
+                title={`${releaseInfo.current.checks.success} passed, ${releaseInfo.current.checks.failure} failed, ${releaseInfo.current.checks.pending} pending`}
+                style={{ color: checkColors[releaseInfo.current.checks.state] || ARGO_GRAY6_COLOR }}
+              >
+                CI {releaseInfo.current.checks.state}
+              </span>
+            )}
           </div>
         </div>
+        {/* Deployed reference without a required verified signature */}
+        {releaseInfo.signature_warning && (
+          <div style={{ fontSize: "12px", color: checkColors.failure, marginTop: "6px" }}>
+            {releaseInfo.signature_warning}
+          </div>
+        )}
+        {/* Distance to the latest release */}
+        {releaseInfo.comparison?.behind_by > 0 && (
+          <div style={{ fontSize: "12px", color: ARGO_GRAY6_COLOR, marginTop: "6px" }}>
+            {releaseInfo.comparison.url ? (
+              <a
+                href={releaseInfo.comparison.url}
+                target="_blank"
+                rel="noopener noreferrer"
+                onClick={(e) => e.stopPropagation()} // Open the comparison without the flyout
+              >
+                {releaseInfo.comparison.behind_by} commit{releaseInfo.comparison.behind_by === 1 ? "" : "s"} behind latest
+              </a>
+            ) : (
+              `${releaseInfo.comparison.behind_by} commit${releaseInfo.comparison.behind_by === 1 ? "" : "s"} behind latest`
+            )}
+          </div>
+        )}
       </div>
     </div>
   );
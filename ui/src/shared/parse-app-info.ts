@@ -28,6 +28,22 @@ function parseAppRepository(infoArray) {
 }
 
 
+function parseReferenceParams(infoArray) {
+  try {
+    // Scope tags to a release stream and track a branch when the matching entries are set
+    const params = [["Tag Prefix", "tagPrefix"], ["Tag Pattern", "tagPattern"], ["Tracked Branch", "branch"]]
+      .map(([name, param]) => {
+        const entry = infoArray.find((item) => item.name === name);
+        return entry && entry.value ? `&${param}=${encodeURIComponent(entry.value.trim())}` : "";
+      });
+    return params.join("");
+  } catch (err) {
+    console.error("Error parsing info array for reference parameters:", err);
+  }
+  return ""; // Return no parameters if parsing fails
+}
+
+
 function findMatchingImage(images, repoName) {
   if (!repoName) return null;
 
@@ -62,9 +78,13 @@ export function getAppDetails(images, info) {
   // Find the matching image
   const imageTag = findMatchingImage(images, imageRepository);
 
-  // Return both values as an object
+  // Extract the optional tag scope and tracked branch, as query parameters
+  const referenceParams = parseReferenceParams(info);
+
+  // Return the values as an object
   return {
     appRepository,
     imageTag,
+    referenceParams,
   };
 }
\ No newline at end of file
@@ -1,14 +1,57 @@
 // Standardized ReleaseEntity fields
 
-interface ReleaseEntity {
+export interface ReleaseEntity {
     ref?: string;          // Git SHA or tag
     url?: string;          // URL to the release or commit
     message?: string;      // Release description or commit message
     published_at?: string; // Publication date
     author?: string;       // Author login
+    prerelease?: boolean;  // Release flagged as a prerelease
+    draft?: boolean;       // Release not yet published
+    tag_kind?: string;     // annotated or lightweight for tags
+    pull_requests?: PullRequest[]; // Pull requests that shipped the current reference
+    checks?: Checks;               // CI checks reported for the commit
+    verification?: Verification;   // Signature of the commit or annotated tag
+}
+
+interface Verification {
+    verified?: boolean; // Whether the signature was verified
+    reason?: string;    // Why the signature is verified or not
+    signer?: string;    // Who signed, empty when unsigned
+}
+
+interface Checks {
+    state?: string;    // success, failure or pending
+    success?: number;  // Number of successful checks
+    failure?: number;  // Number of failed checks
+    pending?: number;  // Number of running checks
+    checks?: { name?: string; state?: string; url?: string }[];
+}
+
+interface PullRequest {
+    number?: number;    // Number of the pull request
+    title?: string;     // Title of the pull request
+    url?: string;       // URL to the pull request
+    author?: string;    // Author login
+    merged_at?: string; // Merge date, empty when not merged
+}
+
+interface Comparison {
+    status?: string;    // identical, behind, ahead or diverged
+    behind_by?: number; // Commits in latest missing from current
+    ahead_by?: number;  // Commits in current missing from latest
+    url?: string;       // URL comparing current with latest
 }
 
 export interface ReleaseInfo {
-    current?: ReleaseEntity; // Current release or commit
-    latest?: ReleaseEntity;  // Latest release or commit
-}
\ No newline at end of file
+    current?: ReleaseEntity;  // Current release or commit
+    latest?: ReleaseEntity;   // Latest release or commit
+    comparison?: Comparison;  // Distance from current to latest
+    signature_warning?: string; // Set when current lacks a required verified signature
+    extraction?: { rule?: string; tag?: string; sha?: string }; // Rule the gitRef was extracted from the image tag by
+}
+export interface PendingReleases {
+    current?: ReleaseEntity;    // Current release or commit
+    latest?: ReleaseEntity;     // Latest release or commit
+    releases?: ReleaseEntity[]; // Releases between current and latest, newest first
+}